@@ -0,0 +1,106 @@
+// Command migrate manages the versioned database schema.
+//
+//	migrate up              apply all pending migrations
+//	migrate down [n]        roll back the last n migrations (default 1)
+//	migrate status          list migrations and when they were applied
+//	migrate create <name>   add an empty up/down pair to the migrations dir
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"appliedTo/internal/platform/config"
+	appdb "appliedTo/internal/platform/db"
+	"appliedTo/internal/platform/db/migrate"
+)
+
+func main() {
+	dir := flag.String("dir", "internal/platform/db/migrations", "migrations directory used by create")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: migrate [-dir path] up | down [n] | status | create <name>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+
+	if cmd == "create" {
+		if len(args) != 1 {
+			log.Fatal("create: migration name required")
+		}
+		existing, err := migrate.Load(os.DirFS(*dir))
+		if err != nil {
+			log.Fatalf("create: %v", err)
+		}
+		up, down, err := migrate.Create(*dir, args[0], existing)
+		if err != nil {
+			log.Fatalf("create: %v", err)
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return
+	}
+
+	cfg := config.Load()
+	db, err := appdb.Connect(cfg)
+	if err != nil {
+		log.Fatalf("db connect: %v", err)
+	}
+	m, err := appdb.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("load migrations: %v", err)
+	}
+	ctx := context.Background()
+
+	switch cmd {
+	case "up":
+		done, err := m.Up(ctx)
+		for _, mg := range done {
+			fmt.Println("applied", mg)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(done) == 0 {
+			fmt.Println("nothing to apply")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				log.Fatalf("down: invalid step count %q", args[0])
+			}
+		}
+		done, err := m.Down(ctx, steps)
+		for _, mg := range done {
+			fmt.Println("rolled back", mg)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, st := range status {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%-45s %s\n", st.Migration, applied)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
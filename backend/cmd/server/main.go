@@ -1,37 +1,76 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"appliedTo/docs"
 	"appliedTo/internal/app/auth"
 	authapi "appliedTo/internal/app/auth/api"
+	"appliedTo/internal/app/company"
+	companyapi "appliedTo/internal/app/company/api"
+	"appliedTo/internal/app/contact"
+	contactapi "appliedTo/internal/app/contact/api"
+	"appliedTo/internal/app/document"
+	documentapi "appliedTo/internal/app/document/api"
 	"appliedTo/internal/app/jobapplication"
 	jobapplicationapi "appliedTo/internal/app/jobapplication/api"
+	"appliedTo/internal/app/reminder"
+	"appliedTo/internal/app/salary"
+	salaryapi "appliedTo/internal/app/salary/api"
+	"appliedTo/internal/app/stats"
+	statsapi "appliedTo/internal/app/stats/api"
 	"appliedTo/internal/app/user"
 	userapi "appliedTo/internal/app/user/api"
 	"appliedTo/internal/platform/config"
 	appdb "appliedTo/internal/platform/db"
 	"appliedTo/internal/platform/http/middleware"
 	"appliedTo/internal/platform/http/routes"
+	"appliedTo/internal/platform/notify"
+	"appliedTo/internal/platform/security/lockout"
 	"appliedTo/internal/platform/security/password"
 	"appliedTo/internal/platform/security/token"
+	"appliedTo/internal/platform/storage"
 )
 
+// shutdownTimeout bounds how long requests in flight may take to finish once
+// the server is told to stop, within the usual grace period of orchestrators.
+const shutdownTimeout = 10 * time.Second
+
+// @title                      AppliedTo API
+// @BasePath                   /api/v1
+// @securityDefinitions.apikey BearerAuth
+// @in                         header
+// @name                       Authorization
+// @description                Access token as "Bearer <token>".
 func main() {
 	cfg := config.Load()
+	if cfg.JWTSecret == "" {
+		log.Fatal("JWT_SECRET must be set")
+	}
 
 	db, err := appdb.Connect(cfg)
 	if err != nil {
 		log.Fatalf("db connect: %v", err)
 	}
-	if err := appdb.Migrate(db); err != nil {
+	if err := appdb.Migrate(context.Background(), db, cfg.DBMigrateOnStart); err != nil {
 		log.Fatalf("db migrate: %v", err)
 	}
 
-	hasher := password.NewBcrypt(password.WithCost(cfg.BcryptCost))
+	hasher, err := newHasher(cfg)
+	if err != nil {
+		log.Fatalf("password hasher: %v", err)
+	}
 	jwtIss := &token.JWT{
 		Secret:    []byte(cfg.JWTSecret),
 		Issuer:    cfg.JWTIssuer,
@@ -39,26 +78,227 @@ func main() {
 	}
 
 	userService := user.NewService(db, hasher)
+	if cfg.AdminEmail != "" {
+		promoted, err := userService.BootstrapAdmin(context.Background(), cfg.AdminEmail)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			log.Printf("warning: ADMIN_EMAIL %s has no account yet; it may register without an invite while there is no admin", cfg.AdminEmail)
+		case err != nil:
+			log.Fatalf("admin %s: %v", cfg.AdminEmail, err)
+		case promoted:
+			log.Printf("made %s the first admin", cfg.AdminEmail)
+		}
+	}
 	userHandlers := userapi.NewHandlers(userService)
 
-	authService := auth.NewService(db, hasher, jwtIss, userService)
+	notifier, err := newNotifier(cfg)
+	if err != nil {
+		log.Fatalf("notifier: %v", err)
+	}
+	if _, ok := notifier.(notify.LogNotifier); ok {
+		if cfg.RequireEmailVerification {
+			log.Fatal("REQUIRE_EMAIL_VERIFICATION needs a NOTIFIER that delivers mail, such as smtp")
+		}
+		log.Print("WARNING: NOTIFIER=log only logs mail, with tokens redacted; " +
+			"email verification and password reset links cannot be used")
+	}
+
+	loginStore, err := newLoginStore(cfg, db)
+	if err != nil {
+		log.Fatalf("login throttle: %v", err)
+	}
+
+	authService := auth.NewService(db, hasher, jwtIss, userService,
+		auth.WithRefreshTTL(cfg.JWTRefreshTTL),
+		auth.WithMailer(notifier),
+		auth.WithAppURL(cfg.AppURL),
+		auth.WithRequireVerifiedEmail(cfg.RequireEmailVerification),
+		auth.WithVerificationTTL(cfg.EmailVerificationTTL),
+		auth.WithPasswordResetTTL(cfg.PasswordResetTTL),
+		auth.WithSelfSignup(cfg.EnableSelfSignup),
+		auth.WithInviteTTL(cfg.InviteTTL),
+		auth.WithBootstrapAdmin(cfg.AdminEmail),
+		auth.WithLoginLimiters(
+			auth.NewAccountLimiter(loginStore, cfg.LoginMaxFailures, cfg.LoginLockout),
+			auth.NewIPLimiter(loginStore, cfg.LoginIPMaxFailures, cfg.LoginLockout),
+		),
+	)
 	authHandlers := authapi.NewHandlers(authService)
 
-	jobApplicationService := jobapplication.NewService(db)
+	salaryService := salary.NewService(db,
+		salary.WithBaseCurrency(cfg.SalaryBaseCurrency),
+		salary.WithHoursPerWeek(cfg.SalaryHoursPerWeek),
+	)
+	if cfg.ExchangeRatesFile != "" {
+		if err := salaryService.LoadFile(context.Background(), cfg.ExchangeRatesFile); err != nil {
+			log.Fatalf("exchange rates: %v", err)
+		}
+	}
+	salaryHandlers := salaryapi.NewHandlers(salaryService)
+
+	jobApplicationService := jobapplication.NewService(db, salaryService)
 	jobApplicationHandlers := jobapplicationapi.NewHandlers(jobApplicationService)
 
+	companyService := company.NewService(db)
+	companyHandlers := companyapi.NewHandlers(companyService, jobApplicationService)
+
+	contactService := contact.NewService(db)
+	contactHandlers := contactapi.NewHandlers(contactService)
+
+	store, err := newStore(cfg)
+	if err != nil {
+		log.Fatalf("storage: %v", err)
+	}
+	documentService := document.NewService(db, store,
+		document.WithMaxBytes(cfg.DocumentMaxBytes),
+	)
+	documentHandlers := documentapi.NewHandlers(documentService)
+
+	statsService := stats.NewService(db, salaryService)
+	statsHandlers := statsapi.NewHandlers(statsService)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.RemindersEnabled {
+		scheduler := reminder.NewScheduler(db, notifier, reminder.Config{
+			Interval:  cfg.ReminderInterval,
+			Lookback:  cfg.ReminderLookback,
+			BatchSize: cfg.ReminderBatchSize,
+		})
+		go scheduler.Run(ctx)
+	}
+
+	requireAuth := middleware.RequireAuth(jwtIss)
+	requireAdmin := middleware.RequireRole(string(user.RoleAdmin))
+
 	docs.SwaggerInfo.BasePath = "/api/v1"
 
-	r := gin.Default()
-	routes.SetupRoutes(r, "/api/v1",
+	routeConfigs := []routes.RouteConfig{
 		authapi.SetupAuthRoutes(authHandlers),
-		userapi.SetupUserRoutes(userHandlers, middleware.RequireUserID()),
-		jobapplicationapi.SetupJobApplicationRoutes(jobApplicationHandlers, middleware.RequireJobApplicationID()),
-	)
+		userapi.SetupUserRoutes(userHandlers, requireAuth),
+		jobapplicationapi.SetupJobApplicationRoutes(jobApplicationHandlers, requireAuth, middleware.RequireJobApplicationID(), middleware.RequireInterviewID()),
+		companyapi.SetupCompanyRoutes(companyHandlers, requireAuth, middleware.RequireCompanyID()),
+		contactapi.SetupContactRoutes(contactHandlers, requireAuth, middleware.RequireContactID()),
+		documentapi.SetupDocumentRoutes(documentHandlers, requireAuth, middleware.RequireDocumentID()),
+		documentapi.SetupJobApplicationDocumentRoutes(documentHandlers, requireAuth, middleware.RequireJobApplicationID(), middleware.RequireDocumentID()),
+		statsapi.SetupStatsRoutes(statsHandlers, requireAuth),
+		salaryapi.SetupExchangeRateRoutes(salaryHandlers, requireAuth),
+	}
+	if !cfg.EnableSelfSignup && !cfg.EnableAdminApi {
+		log.Print("self-signup is disabled but the admin API is off; no invites can be created")
+	}
+	if cfg.EnableAdminApi {
+		routeConfigs = append(routeConfigs,
+			userapi.SetupAdminUserRoutes(userHandlers, routes.AdminRouteOpts{
+				RequireAuth:  requireAuth,
+				RequireAdmin: requireAdmin,
+				RequireID:    middleware.RequireUserID(),
+			}),
+			authapi.SetupAdminInviteRoutes(authHandlers, routes.AdminRouteOpts{
+				RequireAuth:  requireAuth,
+				RequireAdmin: requireAdmin,
+				RequireID:    middleware.RequireInviteID(),
+			}),
+			salaryapi.SetupAdminExchangeRateRoutes(salaryHandlers, routes.AdminRouteOpts{
+				RequireAuth:  requireAuth,
+				RequireAdmin: requireAdmin,
+			}),
+		)
+	}
+
+	r := gin.Default()
+	// The login throttle counts per client IP; only believe forwarding
+	// headers from known proxies.
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("trusted proxies: %v", err)
+	}
+	r.Use(middleware.RequestID())
+	routes.SetupRoutes(r, "/api/v1", routeConfigs...)
+
+	srv := &http.Server{Addr: ":" + cfg.AppPort, Handler: r}
+	errc := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", srv.Addr)
+		errc <- srv.ListenAndServe()
+	}()
 
-	addr := ":" + cfg.AppPort
-	log.Printf("listening on %s", addr)
-	if err := r.Run(addr); err != nil {
+	select {
+	case err := <-errc:
 		log.Fatalf("server: %v", err)
+	case <-ctx.Done():
+	}
+	stop()
+	log.Print("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown: %v", err)
+	}
+}
+
+func newNotifier(cfg config.Config) (notify.Notifier, error) {
+	switch cfg.Notifier {
+	case "", "log":
+		return notify.NewLog(), nil
+	case "smtp":
+		n, err := notify.NewSMTP(notify.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			Timeout:  cfg.SMTPTimeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("unknown NOTIFIER %q", cfg.Notifier)
+	}
+}
+
+func newHasher(cfg config.Config) (password.Hasher, error) {
+	switch cfg.PasswordHasher {
+	case "", "bcrypt":
+		return password.NewBcrypt(password.WithCost(cfg.BcryptCost)), nil
+	case "argon2id":
+		return password.NewArgon2id(
+			password.WithArgon2Memory(cfg.Argon2Memory),
+			password.WithArgon2Time(cfg.Argon2Time),
+			password.WithArgon2Parallelism(cfg.Argon2Parallelism),
+		), nil
+	default:
+		return nil, fmt.Errorf("unknown PASSWORD_HASHER %q", cfg.PasswordHasher)
+	}
+}
+
+func newLoginStore(cfg config.Config, db *gorm.DB) (lockout.Store, error) {
+	switch cfg.LoginThrottleStore {
+	case "", "postgres":
+		return lockout.NewPostgres(db), nil
+	case "memory":
+		return lockout.NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown LOGIN_THROTTLE_STORE %q", cfg.LoginThrottleStore)
+	}
+}
+
+func newStore(cfg config.Config) (storage.Store, error) {
+	switch cfg.StorageBackend {
+	case "", "local":
+		return storage.NewLocal(cfg.StorageDir)
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", cfg.StorageBackend)
 	}
 }
@@ -0,0 +1,20 @@
+package authapi
+
+import (
+	"net/http"
+
+	"appliedTo/internal/app/auth"
+	"appliedTo/internal/platform/http/problem"
+)
+
+func init() {
+	problem.Register(auth.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials", "Invalid email or password")
+	problem.Register(auth.ErrInvalidRefreshToken, http.StatusUnauthorized, "invalid_refresh_token", "Invalid or expired refresh token")
+	problem.Register(auth.ErrEmailNotVerified, http.StatusForbidden, "email_not_verified", "Email address not verified")
+	problem.Register(auth.ErrInvalidToken, http.StatusBadRequest, "invalid_token", "Invalid, used or expired token")
+	problem.Register(auth.ErrInviteRequired, http.StatusForbidden, "invite_required", "Registration requires an invite")
+	problem.Register(auth.ErrInvalidInvite, http.StatusForbidden, "invalid_invite", "Invalid, used or expired invite")
+	problem.Register(auth.ErrInviteUsed, http.StatusConflict, "invite_used", "Invite already used")
+	problem.Register(auth.ErrTooManyAttempts, http.StatusTooManyRequests, "too_many_attempts", "Too many failed login attempts; try again later")
+	problem.Register(auth.ErrRefreshTokenReused, http.StatusUnauthorized, "refresh_token_reused", "Refresh token reuse detected; please log in again")
+}
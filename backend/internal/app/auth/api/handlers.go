@@ -1,12 +1,14 @@
 package authapi
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	auth "appliedTo/internal/app/auth"
-	"appliedTo/internal/app/user"
+	"appliedTo/internal/platform/http/problem"
 )
 
 type Handlers struct {
@@ -17,62 +19,206 @@ func NewHandlers(s *auth.Service) *Handlers { return &Handlers{Svc: s} }
 
 // Login godoc
 // @Summary      Login
-// @Description  Authenticate with email & password and receive a JWT.
+// @Description  Authenticate with email & password and receive a short-lived JWT and a refresh token. Repeated failures slow down further attempts for the account and the client, and eventually lock them out for a while; such attempts are refused with 429 and a Retry-After header.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        payload body  auth.LoginRequest true "Credentials"
 // @Success      200     {object} auth.TokenResponse
-// @Failure      401     {object} map[string]string "Invalid email or password"
-// @Failure      500     {object} map[string]string "Could not generate token"
+// @Failure      400     {object} problem.Problem "Invalid input"
+// @Failure      401     {object} problem.Problem "Invalid email or password"
+// @Failure      403     {object} problem.Problem "Email not verified"
+// @Failure      429     {object} problem.Problem "Too many failed attempts"
+// @Header       429     {integer} Retry-After "Seconds until the next attempt is allowed"
+// @Failure      500     {object} problem.Problem "Could not generate token"
 // @Router       /auth/login [post]
 func (h *Handlers) Login(c *gin.Context) {
 	var req auth.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Input"})
+		problem.InvalidPayload(c)
 		return
 	}
-	tok, err := h.Svc.Authenticate(c.Request.Context(), req.Email, req.Password)
+	resp, err := h.Svc.Authenticate(c.Request.Context(), req.Email, req.Password, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		var locked *auth.LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(locked.RetryAfterSeconds()))
+		}
+		problem.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, auth.TokenResponse{
-		AccessToken: tok,
-        ExpiresIn:   int64(h.Svc.JWT().AccessTTL.Seconds()),
-	})
+	c.JSON(http.StatusOK, resp)
 }
 
 // Register godoc
 // @Summary      Register
-// @Description  Create a new user account and receive a JWT.
+// @Description  Create a new user account and receive a JWT. A verification link is mailed to the address; when login requires a verified email, no tokens are issued and the response is 202 with verification_required set. While self-signup is disabled, an unused and unexpired inviteCode is required; an invite bound to an email only registers that address.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        payload body  auth.RegisterRequest true "New user"
-// @Success      200     {object} auth.TokenResponse "User registered"
-// @Failure      400     {object} map[string]string "Invalid input"
-// @Failure      409     {object} map[string]string "E-Mail already in use"
-// @Failure      500     {object} map[string]string "Could not create user"
+// @Success      200     {object} auth.RegisterResponse "User registered"
+// @Success      202     {object} auth.RegisterResponse "User registered, email verification required"
+// @Failure      400     {object} problem.Problem "Invalid input"
+// @Failure      403     {object} problem.Problem "Invite required or invalid"
+// @Failure      409     {object} problem.Problem "E-Mail already in use"
+// @Failure      500     {object} problem.Problem "Could not create user"
 // @Router       /auth/register [post]
 func (h *Handlers) Register(c *gin.Context) {
 	var req auth.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		problem.InvalidPayload(c)
 		return
 	}
-	tok, err := h.Svc.Register(c.Request.Context(), req)
+	resp, err := h.Svc.Register(c.Request.Context(), req)
 	if err != nil {
-		switch err {
-		case user.ErrEmailInUse:
-			c.JSON(http.StatusConflict, gin.H{"error": "E-Mail already in use"})
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		problem.Error(c, err)
+		return
+	}
+	if resp.VerificationRequired {
+		c.JSON(http.StatusAccepted, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Refresh godoc
+// @Summary      Refresh tokens
+// @Description  Exchange a refresh token for a new access token and refresh token. Each refresh token works once; reusing one revokes every token of its login.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload body  auth.RefreshRequest true "Refresh token"
+// @Success      200     {object} auth.TokenResponse
+// @Failure      400     {object} problem.Problem "Invalid input"
+// @Failure      401     {object} problem.Problem "Invalid, expired or reused refresh token"
+// @Failure      500     {object} problem.Problem "Could not refresh tokens"
+// @Router       /auth/refresh [post]
+func (h *Handlers) Refresh(c *gin.Context) {
+	var req auth.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+	resp, err := h.Svc.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Logout godoc
+// @Summary      Logout
+// @Description  Revoke the refresh token and every token issued from the same login. Access tokens stay valid until they expire.
+// @Tags         auth
+// @Accept       json
+// @Param        payload body  auth.RefreshRequest true "Refresh token"
+// @Success      204
+// @Failure      400     {object} problem.Problem "Invalid input"
+// @Failure      500     {object} problem.Problem "Could not log out"
+// @Router       /auth/logout [post]
+func (h *Handlers) Logout(c *gin.Context) {
+	var req auth.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+	if err := h.Svc.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email address
+// @Description  Marks the email address verified with the token from the verification mail. Tokens work once.
+// @Tags         auth
+// @Accept       json
+// @Param        payload body  auth.VerifyEmailRequest true "Verification token"
+// @Success      204
+// @Failure      400     {object} problem.Problem "Invalid, used or expired token"
+// @Failure      500     {object} problem.Problem "Could not verify email"
+// @Router       /auth/email/verify [post]
+func (h *Handlers) VerifyEmail(c *gin.Context) {
+	var req auth.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+	if err := h.Svc.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification mail
+// @Description  Mails a new verification link if an unverified account has this address. The response is the same either way.
+// @Tags         auth
+// @Accept       json
+// @Param        payload body  auth.EmailRequest true "Email address"
+// @Success      202
+// @Failure      400     {object} problem.Problem "Invalid input"
+// @Failure      500     {object} problem.Problem "Could not send mail"
+// @Router       /auth/email/verify/resend [post]
+func (h *Handlers) ResendVerification(c *gin.Context) {
+	var req auth.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+	if err := h.Svc.ResendVerification(c.Request.Context(), req.Email); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Mails a single-use, time-limited password reset link if an account has this address. The response is the same either way.
+// @Tags         auth
+// @Accept       json
+// @Param        payload body  auth.EmailRequest true "Email address"
+// @Success      202
+// @Failure      400     {object} problem.Problem "Invalid input"
+// @Failure      500     {object} problem.Problem "Could not send mail"
+// @Router       /auth/password/forgot [post]
+func (h *Handlers) ForgotPassword(c *gin.Context) {
+	var req auth.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+	if err := h.Svc.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Sets a new password with the token from the reset mail and logs out every session of the account. Tokens work once.
+// @Tags         auth
+// @Accept       json
+// @Param        payload body  auth.PasswordResetRequest true "Reset token and new password"
+// @Success      204
+// @Failure      400     {object} problem.Problem "Invalid input or invalid, used or expired token"
+// @Failure      500     {object} problem.Problem "Could not reset password"
+// @Router       /auth/password/reset [post]
+func (h *Handlers) ResetPassword(c *gin.Context) {
+	var req auth.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+	if err := h.Svc.ResetPassword(c.Request.Context(), req); err != nil {
+		problem.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, auth.TokenResponse{
-		AccessToken: tok,
-        ExpiresIn:   int64(h.Svc.JWT().AccessTTL.Seconds()),
-	})
+	c.Status(http.StatusNoContent)
 }
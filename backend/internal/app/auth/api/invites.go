@@ -0,0 +1,99 @@
+package authapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	auth "appliedTo/internal/app/auth"
+	"appliedTo/internal/platform/http/middleware"
+	"appliedTo/internal/platform/http/problem"
+)
+
+// CreateInvite godoc
+// @Summary      Create an invite
+// @Description  Issues a single-use invite code for registration while self-signup is disabled. The code is only returned here. Admins only.
+// @Tags         admin
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload body  auth.InviteCreateDto true "Invite"
+// @Success      201     {object} auth.InviteCreatedDto "Invite with its code"
+// @Failure      400     {object} problem.Problem "Invalid input"
+// @Failure      401     {object} problem.Problem "Authentication required"
+// @Failure      403     {object} problem.Problem "Admins only"
+// @Failure      500     {object} problem.Problem "Could not create invite"
+// @Router       /admin/invites [post]
+func (h *Handlers) CreateInvite(c *gin.Context) {
+	adminID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var in auth.InviteCreateDto
+	if err := c.ShouldBindJSON(&in); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.CreateInvite(c.Request.Context(), adminID, in)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"invite": out})
+}
+
+// ListInvites godoc
+// @Summary      List invites
+// @Description  Lists invites, newest first. Admins only.
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        status  query  string  false  "Only invites with this status"  Enums(pending, used, revoked, expired)
+// @Success      200     {object} map[string][]auth.InvitePublicDto "Invites"
+// @Failure      400     {object} problem.Problem "Invalid status"
+// @Failure      401     {object} problem.Problem "Authentication required"
+// @Failure      403     {object} problem.Problem "Admins only"
+// @Failure      500     {object} problem.Problem "Database query failed"
+// @Router       /admin/invites [get]
+func (h *Handlers) ListInvites(c *gin.Context) {
+	var q auth.InviteListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.ListInvites(c.Request.Context(), q)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invites": out})
+}
+
+// RevokeInvite godoc
+// @Summary      Revoke an invite
+// @Description  Makes an unused invite unusable. Admins only.
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path  int  true  "Invite ID"
+// @Success      200  {object} map[string]auth.InvitePublicDto "Revoked invite"
+// @Failure      400  {object} problem.Problem "Invalid ID"
+// @Failure      401  {object} problem.Problem "Authentication required"
+// @Failure      403  {object} problem.Problem "Admins only"
+// @Failure      404  {object} problem.Problem "Invite not found"
+// @Failure      409  {object} problem.Problem "Invite already used"
+// @Failure      500  {object} problem.Problem "Could not revoke invite"
+// @Router       /admin/invites/{id} [delete]
+func (h *Handlers) RevokeInvite(c *gin.Context) {
+	id := c.GetUint(middleware.CtxKeyInviteID)
+
+	out, err := h.Svc.RevokeInvite(c.Request.Context(), id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invite": out})
+}
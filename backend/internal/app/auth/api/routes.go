@@ -11,7 +11,25 @@ func SetupAuthRoutes(h *Handlers) routes.RouteConfig {
         Register: func(g *gin.RouterGroup) {
             g.POST("/login", h.Login)
             g.POST("/register", h.Register)
+            g.POST("/refresh", h.Refresh)
+            g.POST("/logout", h.Logout)
+            g.POST("/email/verify", h.VerifyEmail)
+            g.POST("/email/verify/resend", h.ResendVerification)
+            g.POST("/password/forgot", h.ForgotPassword)
+            g.POST("/password/reset", h.ResetPassword)
         },
     }
 }
 
+
+func SetupAdminInviteRoutes(h *Handlers, opts routes.AdminRouteOpts) routes.RouteConfig {
+    return routes.RouteConfig{
+        Prefix: "/admin/invites",
+        Use:    []gin.HandlerFunc{opts.RequireAuth, opts.RequireAdmin},
+        Register: func(g *gin.RouterGroup) {
+            g.GET("", h.ListInvites)
+            g.POST("", h.CreateInvite)
+            g.DELETE("/:id", opts.RequireID, h.RevokeInvite)
+        },
+    }
+}
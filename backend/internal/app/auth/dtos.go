@@ -1,5 +1,7 @@
 package auth
 
+import "time"
+
 type LoginRequest struct {
 	Email    string `json:"email" example:"user@example.com"`
 	Password string `json:"password" example:"secret123"`
@@ -10,9 +12,65 @@ type RegisterRequest struct {
 	LastName  string `json:"lastName"`
 	Email     string `json:"email"`
 	Password  string `json:"password"`
+	// InviteCode is required while self-signup is disabled.
+	InviteCode string `json:"inviteCode,omitempty"`
 }
 
 type TokenResponse struct {
-	AccessToken string `json:"access_token"`
-	ExpiresIn int64 `json:"expires_in"`
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RegisterResponse carries the tokens of a new account, unless login waits
+// for email verification.
+type RegisterResponse struct {
+	*TokenResponse
+	VerificationRequired bool `json:"verification_required,omitempty"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required" example:"user@example.com"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type PasswordResetRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type InviteCreateDto struct {
+	// Email, if set, is the only address the invite registers.
+	Email *string `json:"email,omitempty"`
+	// ExpiresAt defaults to the configured invite lifetime from now.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type InvitePublicDto struct {
+	ID        uint       `json:"id"`
+	Email     *string    `json:"email,omitempty"`
+	Status    string     `json:"status"`
+	CreatedBy *uint      `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	UsedBy    *uint      `json:"usedBy,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// InviteCreatedDto carries the invite code, which is not shown again.
+type InviteCreatedDto struct {
+	InvitePublicDto
+	Code string `json:"code"`
+}
+
+type InviteListQuery struct {
+	Status string `form:"status"`
 }
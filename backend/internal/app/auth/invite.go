@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"appliedTo/internal/platform/validate"
+)
+
+var (
+	ErrInviteRequired = errors.New("registration requires an invite")
+	ErrInvalidInvite  = errors.New("invalid, used or expired invite")
+	ErrInviteUsed     = errors.New("invite already used")
+)
+
+const defaultInviteTTL = 7 * 24 * time.Hour
+
+// CREATE
+// CreateInvite issues an invite on behalf of the admin createdBy. The
+// returned code is not stored and cannot be shown again.
+func (s *Service) CreateInvite(ctx context.Context, createdBy uint, in InviteCreateDto) (InviteCreatedDto, error) {
+	now := time.Now()
+	inv := Invite{CreatedByUserID: &createdBy, ExpiresAt: now.Add(s.inviteTTL)}
+
+	var errs validate.Errors
+	if in.Email != nil {
+		email, err := validate.NormalizeAndValidateEmail(*in.Email)
+		if err != nil {
+			errs.Add("email", "must be a valid email address")
+		}
+		inv.Email = &email
+	}
+	if in.ExpiresAt != nil {
+		if !in.ExpiresAt.After(now) {
+			errs.Add("expiresAt", "must be in the future")
+		}
+		inv.ExpiresAt = *in.ExpiresAt
+	}
+	if err := errs.Err(); err != nil {
+		return InviteCreatedDto{}, err
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		return InviteCreatedDto{}, err
+	}
+	inv.CodeHash = hashToken(code)
+	if err := s.db.WithContext(ctx).Create(&inv).Error; err != nil {
+		return InviteCreatedDto{}, err
+	}
+	return InviteCreatedDto{InvitePublicDto: MapInviteToPublicDto(inv, now), Code: code}, nil
+}
+
+// READ
+// ListInvites returns all invites, newest first, optionally only those with
+// the given status.
+func (s *Service) ListInvites(ctx context.Context, in InviteListQuery) ([]InvitePublicDto, error) {
+	now := time.Now()
+	q := s.db.WithContext(ctx)
+	switch InviteStatus(in.Status) {
+	case "":
+	case InvitePending:
+		q = q.Where("used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+	case InviteUsed:
+		q = q.Where("used_at IS NOT NULL")
+	case InviteRevoked:
+		q = q.Where("used_at IS NULL AND revoked_at IS NOT NULL")
+	case InviteExpired:
+		q = q.Where("used_at IS NULL AND revoked_at IS NULL AND expires_at <= ?", now)
+	default:
+		var errs validate.Errors
+		validate.OneOf(&errs, "status", InviteStatus(in.Status), InviteStatuses...)
+		return nil, errs.Err()
+	}
+
+	var invites []Invite
+	if err := q.Order("id DESC").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	out := make([]InvitePublicDto, 0, len(invites))
+	for _, inv := range invites {
+		out = append(out, MapInviteToPublicDto(inv, now))
+	}
+	return out, nil
+}
+
+// DELETE
+// RevokeInvite makes an unused invite unusable. Revoking it again is a no-op.
+func (s *Service) RevokeInvite(ctx context.Context, id uint) (InvitePublicDto, error) {
+	var inv Invite
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inv, id).Error; err != nil {
+			return err
+		}
+		if inv.UsedAt != nil {
+			return ErrInviteUsed
+		}
+		if inv.RevokedAt != nil {
+			return nil
+		}
+		now := time.Now()
+		inv.RevokedAt = &now
+		return tx.Model(&inv).Update("revoked_at", now).Error
+	})
+	if err != nil {
+		return InvitePublicDto{}, err
+	}
+	return MapInviteToPublicDto(inv, time.Now()), nil
+}
+
+// -------- helpers --------
+
+// redeemInvite locks the invite with the given code and marks it used by
+// the user that register creates in the same transaction. Unknown, used,
+// revoked and expired codes, and codes bound to another email, are all
+// ErrInvalidInvite.
+func redeemInvite(tx *gorm.DB, code, email string, register func(tx *gorm.DB) (uint, error)) error {
+	var inv Invite
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code_hash = ?", hashToken(code)).
+		First(&inv).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidInvite
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if inv.Status(now) != InvitePending {
+		return ErrInvalidInvite
+	}
+	if inv.Email != nil {
+		normalized, err := validate.NormalizeAndValidateEmail(email)
+		if err != nil || normalized != *inv.Email {
+			return ErrInvalidInvite
+		}
+	}
+
+	userID, err := register(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&inv).Updates(map[string]any{
+		"used_at":         now,
+		"used_by_user_id": userID,
+	}).Error
+}
+
+func MapInviteToPublicDto(m Invite, now time.Time) InvitePublicDto {
+	return InvitePublicDto{
+		ID:        m.ID,
+		Email:     m.Email,
+		Status:    string(m.Status(now)),
+		CreatedBy: m.CreatedByUserID,
+		CreatedAt: m.CreatedAt.UTC(),
+		ExpiresAt: m.ExpiresAt.UTC(),
+		UsedAt:    m.UsedAt,
+		UsedBy:    m.UsedByUserID,
+		RevokedAt: m.RevokedAt,
+	}
+}
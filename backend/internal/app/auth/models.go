@@ -0,0 +1,99 @@
+package auth
+
+import "time"
+
+// RefreshToken is one opaque refresh token, stored as its SHA-256 hash.
+// Tokens issued from the same login share a FamilyID; each refresh marks the
+// presented token used and issues its replacement in the same family.
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey"`
+	UserID       uint       `gorm:"index;not null"`
+	FamilyID     string     `gorm:"size:32;index;not null"`
+	TokenHash    string     `gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt    time.Time  `gorm:"not null"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+	UsedAt       *time.Time
+	RevokedAt    *time.Time
+	ReplacedByID *uint
+}
+
+// Invite lets one person register while self-signup is off. The code is
+// shown once on creation and stored as its SHA-256 hash. An invite bound to
+// an email only registers that address.
+type Invite struct {
+	ID              uint      `gorm:"primaryKey"`
+	CodeHash        string    `gorm:"size:64;uniqueIndex;not null"`
+	Email           *string   `gorm:"size:320"`
+	CreatedByUserID *uint     `gorm:"index"`
+	ExpiresAt       time.Time `gorm:"not null"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UsedAt          *time.Time
+	UsedByUserID    *uint
+	RevokedAt       *time.Time
+}
+
+// InviteStatus is derived from an invite's timestamps.
+type InviteStatus string
+
+const (
+	InvitePending InviteStatus = "pending"
+	InviteUsed    InviteStatus = "used"
+	InviteRevoked InviteStatus = "revoked"
+	InviteExpired InviteStatus = "expired"
+)
+
+var InviteStatuses = []InviteStatus{InvitePending, InviteUsed, InviteRevoked, InviteExpired}
+
+func (i Invite) Status(now time.Time) InviteStatus {
+	switch {
+	case i.UsedAt != nil:
+		return InviteUsed
+	case i.RevokedAt != nil:
+		return InviteRevoked
+	case !now.Before(i.ExpiresAt):
+		return InviteExpired
+	}
+	return InvitePending
+}
+
+// OneTimeToken is a single-use token mailed to a user at Email, stored as
+// its SHA-256 hash. It stops working once the user's email changes. Issuing
+// a new token for a purpose discards the user's unused ones.
+type OneTimeToken struct {
+	ID        uint         `gorm:"primaryKey"`
+	UserID    uint         `gorm:"not null"`
+	Email     string       `gorm:"size:320;not null"`
+	Purpose   TokenPurpose `gorm:"size:24;not null"`
+	TokenHash string       `gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time    `gorm:"not null"`
+	CreatedAt time.Time    `gorm:"autoCreateTime"`
+	UsedAt    *time.Time
+}
+
+type TokenPurpose string
+
+const (
+	PurposeVerifyEmail   TokenPurpose = "verify_email"
+	PurposeResetPassword TokenPurpose = "reset_password"
+)
+
+// AuditEvent records a security relevant event of the login flow, such as a
+// lockout. UserID is set when the event concerns a known account.
+type AuditEvent struct {
+	ID        uint           `gorm:"primaryKey"`
+	Type      AuditEventType `gorm:"size:32;not null"`
+	UserID    *uint          `gorm:"index"`
+	Email     *string        `gorm:"size:320"`
+	IP        *string        `gorm:"size:64"`
+	Until     *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (AuditEvent) TableName() string { return "auth_audit_events" }
+
+type AuditEventType string
+
+const (
+	AuditLockoutAccount AuditEventType = "login_lockout_account"
+	AuditLockoutIP      AuditEventType = "login_lockout_ip"
+)
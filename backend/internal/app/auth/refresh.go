@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+const defaultRefreshTTL = 30 * 24 * time.Hour
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is used up; presenting it again revokes its whole family, since only a
+// stolen copy would still be in circulation.
+func (s *Service) Refresh(ctx context.Context, raw string) (TokenResponse, error) {
+	var (
+		next   string
+		userID uint
+		reused bool
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rt RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hashToken(raw)).
+			First(&rt).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if rt.UsedAt != nil || rt.RevokedAt != nil {
+			reused = true
+			return revokeFamily(tx, rt.FamilyID, now)
+		}
+		if !now.Before(rt.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		replacement, plain, err := s.newRefreshToken(rt.UserID, rt.FamilyID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&replacement).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&rt).Updates(map[string]any{
+			"used_at":        now,
+			"replaced_by_id": replacement.ID,
+		}).Error; err != nil {
+			return err
+		}
+		next, userID = plain, rt.UserID
+		return nil
+	})
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	if reused {
+		return TokenResponse{}, ErrRefreshTokenReused
+	}
+
+	u, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return TokenResponse{}, ErrInvalidRefreshToken
+	}
+	access, err := s.signAccess(u.ID, u.Email, u.Role)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	return s.tokenResponse(access, next), nil
+}
+
+// Logout revokes the family of the given refresh token, ending that login on
+// every device that shares it. Unknown tokens are ignored.
+func (s *Service) Logout(ctx context.Context, raw string) error {
+	var rt RefreshToken
+	err := s.db.WithContext(ctx).Where("token_hash = ?", hashToken(raw)).First(&rt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return revokeFamily(s.db.WithContext(ctx), rt.FamilyID, time.Now())
+}
+
+// issueRefreshToken starts a new token family for a fresh login.
+func (s *Service) issueRefreshToken(ctx context.Context, userID uint) (string, error) {
+	family, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	rt, plain, err := s.newRefreshToken(userID, family)
+	if err != nil {
+		return "", err
+	}
+	if err := s.db.WithContext(ctx).Create(&rt).Error; err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+func (s *Service) newRefreshToken(userID uint, family string) (RefreshToken, string, error) {
+	plain, err := randomToken()
+	if err != nil {
+		return RefreshToken{}, "", err
+	}
+	return RefreshToken{
+		UserID:    userID,
+		FamilyID:  family,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}, plain, nil
+}
+
+func revokeFamily(tx *gorm.DB, family string, now time.Time) error {
+	return tx.Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", now).Error
+}
+
+// ---- helpers ----
+
+// randomToken returns an opaque, URL-safe token with 256 bits of entropy.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"appliedTo/internal/app/user"
+)
+
+func TestRegisterBootstrapsFirstAdmin(t *testing.T) {
+	s, db, _ := newTestService(t, WithSelfSignup(false), WithBootstrapAdmin("Admin@Example.com"))
+	ctx := context.Background()
+	registerAs := func(email string) error {
+		_, err := s.Register(ctx, RegisterRequest{FirstName: "Ada", LastName: "Admin", Email: email, Password: testPassword})
+		return err
+	}
+
+	if err := registerAs("jane@example.com"); !errors.Is(err, ErrInviteRequired) {
+		t.Fatalf("Register(other email) error = %v, want ErrInviteRequired", err)
+	}
+	if err := registerAs("admin@example.com"); err != nil {
+		t.Fatalf("Register(admin email) error = %v", err)
+	}
+	var u user.User
+	if err := db.Where("email = ?", "admin@example.com").Take(&u).Error; err != nil {
+		t.Fatal(err)
+	}
+	if u.Role != user.RoleAdmin {
+		t.Errorf("role = %q, want admin", u.Role)
+	}
+
+	// Once there is an admin, nobody else bootstraps, and a demoted admin
+	// stays demoted.
+	if err := db.Model(&u).Update("role", user.RoleUser).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&user.User{FirstName: "B", LastName: "B", Email: "boss@example.com", Password: "x", Role: user.RoleAdmin}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if promoted, err := s.users.BootstrapAdmin(ctx, "admin@example.com"); err != nil || promoted {
+		t.Errorf("BootstrapAdmin() with an admin = %v, %v, want false, nil", promoted, err)
+	}
+}
@@ -3,12 +3,16 @@ package auth
 import (
 	"context"
 	"errors"
+	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
 	"appliedTo/internal/app/user"
+	"appliedTo/internal/platform/notify"
+	"appliedTo/internal/platform/security/lockout"
 	"appliedTo/internal/platform/security/password"
 	"appliedTo/internal/platform/security/token"
 	"appliedTo/internal/platform/validate"
@@ -19,42 +23,181 @@ var (
 )
 
 type Service struct {
-	db     *gorm.DB
-	hasher password.Hasher
-	jwt    *token.JWT
-	users  *user.Service
+	db         *gorm.DB
+	hasher     password.Hasher
+	jwt        *token.JWT
+	users      *user.Service
+	refreshTTL time.Duration
+	selfSignup bool
+	inviteTTL  time.Duration
+	adminEmail string
+
+	mailer          notify.Notifier
+	mailSlots       chan struct{}
+	appURL          string
+	requireVerified bool
+	verifyTTL       time.Duration
+	resetTTL        time.Duration
+
+	accountLimiter *lockout.Limiter
+	ipLimiter      *lockout.Limiter
+}
+
+// ---- Options pattern ----
+
+type Option func(*Service)
+
+// WithRefreshTTL sets how long a refresh token stays valid.
+func WithRefreshTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.refreshTTL = ttl
+		}
+	}
+}
+
+// WithSelfSignup decides whether anyone may register. When disabled,
+// registration requires an invite.
+func WithSelfSignup(enabled bool) Option {
+	return func(s *Service) { s.selfSignup = enabled }
+}
+
+// WithBootstrapAdmin lets the user with the given email register without an
+// invite, and become an admin, as long as there is no admin yet. It is how
+// the first admin signs up when self-signup is disabled.
+func WithBootstrapAdmin(email string) Option {
+	return func(s *Service) {
+		if norm, err := validate.NormalizeAndValidateEmail(email); err == nil {
+			s.adminEmail = norm
+		}
+	}
+}
+
+// WithInviteTTL sets how long a new invite stays valid by default.
+func WithInviteTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.inviteTTL = ttl
+		}
+	}
+}
+
+// WithMailer sets where verification and password reset mail goes. It
+// defaults to the log.
+func WithMailer(n notify.Notifier) Option {
+	return func(s *Service) {
+		if n != nil {
+			s.mailer = n
+		}
+	}
+}
+
+// WithAppURL sets the address of the web app that mailed links point to.
+// Without it, mail only contains the token.
+func WithAppURL(url string) Option {
+	return func(s *Service) { s.appURL = strings.TrimRight(url, "/") }
+}
+
+// WithRequireVerifiedEmail refuses logins, and tokens on registration,
+// until the user has verified their email address.
+func WithRequireVerifiedEmail(required bool) Option {
+	return func(s *Service) { s.requireVerified = required }
 }
 
-func NewService(db *gorm.DB, hasher password.Hasher, jwt *token.JWT, users *user.Service) *Service {
-	return &Service{db: db, hasher: hasher, jwt: jwt, users: users}
+// WithVerificationTTL sets how long an email verification token stays valid.
+func WithVerificationTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.verifyTTL = ttl
+		}
+	}
+}
+
+// WithPasswordResetTTL sets how long a password reset token stays valid.
+func WithPasswordResetTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.resetTTL = ttl
+		}
+	}
 }
 
-func (s *Service) Authenticate(ctx context.Context, email, plain string) (string, error) {
+// WithLoginLimiters sets how failed logins are throttled per account and per
+// client IP. They default to counters in the process.
+func WithLoginLimiters(account, ip *lockout.Limiter) Option {
+	return func(s *Service) {
+		if account != nil && ip != nil {
+			s.accountLimiter, s.ipLimiter = account, ip
+		}
+	}
+}
+
+func NewService(db *gorm.DB, hasher password.Hasher, jwt *token.JWT, users *user.Service, opts ...Option) *Service {
+	s := &Service{
+		db:         db,
+		hasher:     hasher,
+		jwt:        jwt,
+		users:      users,
+		refreshTTL: defaultRefreshTTL,
+		selfSignup: true,
+		inviteTTL:  defaultInviteTTL,
+		mailer:     notify.NewLog(),
+		mailSlots:  make(chan struct{}, maxPendingMails),
+		verifyTTL:  defaultVerifyTTL,
+		resetTTL:   defaultResetTTL,
+	}
+	s.accountLimiter, s.ipLimiter = defaultLoginLimiters()
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Authenticate checks the credentials of a login from the client at ip.
+// Every attempt is counted per account and per client before the password
+// is checked, and taken back if it succeeds; past a few failures, attempts
+// have to wait, and eventually both are locked out for a while. Waiting
+// attempts fail with a LockedError without checking the password.
+func (s *Service) Authenticate(ctx context.Context, email, plain, ip string) (TokenResponse, error) {
 	normalizedEmail, err := validate.NormalizeAndValidateEmail(email)
 	if err != nil {
-		return "", ErrInvalidCredentials
+		return TokenResponse{}, ErrInvalidCredentials
+	}
+	attempt, err := s.reserveAttempt(ctx, normalizedEmail, ip)
+	if err != nil {
+		return TokenResponse{}, err
 	}
 
 	var u user.User
 	if err := s.db.WithContext(ctx).Where("email = ?", normalizedEmail).First(&u).Error; err != nil {
-		return "", ErrInvalidCredentials
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.cancel(ctx, attempt)
+			return TokenResponse{}, err
+		}
+		s.failed(ctx, attempt, nil)
+		return TokenResponse{}, ErrInvalidCredentials
 	}
 	if !s.hasher.Verify(u.Password, plain) {
-		return "", ErrInvalidCredentials
+		s.failed(ctx, attempt, &u.ID)
+		return TokenResponse{}, ErrInvalidCredentials
 	}
-
-	claims := map[string]any{
-		"sub": strconv.FormatUint(uint64(u.ID), 10),
-		"eml": u.Email,
+	s.succeeded(ctx, attempt)
+	if s.hasher.NeedsRehash(u.Password) {
+		s.rehash(ctx, u, plain)
 	}
-	tok, err := s.jwt.Sign(claims)
-	if err != nil {
-		return "", err
+	if s.requireVerified && u.EmailVerifiedAt == nil {
+		return TokenResponse{}, ErrEmailNotVerified
 	}
-	return tok, nil
+
+	return s.login(ctx, u.ID, u.Email, u.Role)
 }
 
-func (s *Service) Register(ctx context.Context, in RegisterRequest) (string, error) {
+// Register creates an account, mails it a verification link and, unless
+// login waits for verification, logs it in. With an invite code, the invite
+// is redeemed in the same transaction; without one, registration fails with
+// ErrInviteRequired unless self-signup is enabled or it signs up the first
+// admin (see WithBootstrapAdmin).
+func (s *Service) Register(ctx context.Context, in RegisterRequest) (RegisterResponse, error) {
 	dto := user.UserCreateDto{
 		BaseUserDto: user.BaseUserDto{
 			FirstName: strings.TrimSpace(in.FirstName),
@@ -63,20 +206,124 @@ func (s *Service) Register(ctx context.Context, in RegisterRequest) (string, err
 		},
 		Password:  in.Password,
 	}
-	userPublic, id, err := s.users.Create(ctx, dto)
+	var (
+		userPublic user.UserPublicDto
+		id         uint
+		err        error
+	)
+	if code := strings.TrimSpace(in.InviteCode); code != "" {
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return redeemInvite(tx, code, in.Email, func(tx *gorm.DB) (uint, error) {
+				userPublic, id, err = s.users.WithDB(tx).Create(ctx, dto)
+				return id, err
+			})
+		})
+	} else if s.selfSignup {
+		userPublic, id, err = s.users.Create(ctx, dto)
+	} else {
+		userPublic, id, err = s.registerAdmin(ctx, dto)
+	}
 	if err != nil {
-		return "", err
+		return RegisterResponse{}, err
+	}
+
+	if err := s.sendVerification(ctx, id, userPublic.Email); err != nil {
+		return RegisterResponse{}, err
+	}
+	if s.requireVerified {
+		return RegisterResponse{VerificationRequired: true}, nil
 	}
 	//auto-login on registration
+	tokens, err := s.login(ctx, id, userPublic.Email, user.Role(userPublic.Role))
+	if err != nil {
+		return RegisterResponse{}, err
+	}
+	return RegisterResponse{TokenResponse: &tokens}, nil
+}
+
+func (s *Service) JWT() *token.JWT { return s.jwt }
+
+// registerAdmin creates the first admin configured with WithBootstrapAdmin.
+// Anyone else gets ErrInviteRequired.
+func (s *Service) registerAdmin(ctx context.Context, dto user.UserCreateDto) (user.UserPublicDto, uint, error) {
+	email, err := validate.NormalizeAndValidateEmail(dto.Email)
+	if s.adminEmail == "" || err != nil || email != s.adminEmail {
+		return user.UserPublicDto{}, 0, ErrInviteRequired
+	}
+	var (
+		userPublic user.UserPublicDto
+		id         uint
+	)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		users := s.users.WithDB(tx)
+		hasAdmin, err := users.HasAdmin(ctx)
+		if err != nil {
+			return err
+		}
+		if hasAdmin {
+			return ErrInviteRequired
+		}
+		if userPublic, id, err = users.Create(ctx, dto); err != nil {
+			return err
+		}
+		if _, err := users.BootstrapAdmin(ctx, email); err != nil {
+			return err
+		}
+		userPublic.Role = string(user.RoleAdmin)
+		return nil
+	})
+	return userPublic, id, err
+}
+
+// -------- helpers --------
+
+// login issues the access token and starts a new refresh token family.
+func (s *Service) login(ctx context.Context, userID uint, email string, role user.Role) (TokenResponse, error) {
+	access, err := s.signAccess(userID, email, role)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	refresh, err := s.issueRefreshToken(ctx, userID)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	return s.tokenResponse(access, refresh), nil
+}
+
+func (s *Service) signAccess(userID uint, email string, role user.Role) (string, error) {
 	claims := map[string]any{
-		"sub": strconv.FormatUint(uint64(id), 10),
-		"eml": userPublic.Email,
+		"sub": strconv.FormatUint(uint64(userID), 10),
+		"eml": email,
+		"rol": string(role),
+	}
+	return s.jwt.Sign(claims)
+}
+
+// rehash replaces the stored hash of u with one made with the current hasher
+// settings. It only logs failures, which leave the old hash working, and
+// keeps a password changed concurrently.
+func (s *Service) rehash(ctx context.Context, u user.User, plain string) {
+	hash, err := s.hasher.Hash(plain)
+	if err == nil {
+		err = s.db.WithContext(ctx).Model(&user.User{}).
+			Where("id = ? AND password = ?", u.ID, u.Password).
+			Update("password", hash).Error
 	}
-	tok, err := s.jwt.Sign(claims)
 	if err != nil {
-		return "", err
+		log.Printf("rehash password of user %d: %v", u.ID, err)
 	}
-	return tok, nil
 }
 
-func (s *Service) JWT() *token.JWT { return s.jwt }
+func (s *Service) tokenResponse(access, refresh string) TokenResponse {
+	return TokenResponse{
+		AccessToken:  access,
+		ExpiresIn:    int64(s.jwt.AccessTTL.Seconds()),
+		RefreshToken: refresh,
+	}
+}
+
+func (s *Service) loadUser(ctx context.Context, id uint) (user.User, error) {
+	var u user.User
+	err := s.db.WithContext(ctx).First(&u, id).Error
+	return u, err
+}
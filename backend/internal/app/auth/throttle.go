@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"appliedTo/internal/platform/security/lockout"
+)
+
+var ErrTooManyAttempts = errors.New("too many login attempts")
+
+// LockedError refuses a login attempt of an account or client that failed
+// too often. It matches ErrTooManyAttempts.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string { return ErrTooManyAttempts.Error() }
+func (e *LockedError) Unwrap() error { return ErrTooManyAttempts }
+
+// RetryAfterSeconds is RetryAfter rounded up, as sent in the Retry-After
+// header.
+func (e *LockedError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+func (e *LockedError) ProblemExtensions() map[string]any {
+	return map[string]any{"retryAfter": e.RetryAfterSeconds()}
+}
+
+// defaultLoginLimiters keep their state in the process; replicas should
+// share a Postgres store through WithLoginLimiters.
+func defaultLoginLimiters() (account, ip *lockout.Limiter) {
+	store := lockout.NewMemory()
+	return NewAccountLimiter(store, 10, 15*time.Minute), NewIPLimiter(store, 50, 15*time.Minute)
+}
+
+// NewAccountLimiter throttles login attempts per email address: three free
+// failures, then a doubling delay, and a lockout once maxFailures is reached.
+// Refused attempts do not count, so that anyone who knows the address cannot
+// keep the account locked out by trying it.
+func NewAccountLimiter(store lockout.Store, maxFailures int, lockoutFor time.Duration) *lockout.Limiter {
+	return lockout.New(store, "account:",
+		lockout.WithFreeAttempts(3),
+		lockout.WithBackoff(time.Second, 5*time.Minute),
+		lockout.WithLockout(maxFailures, lockoutFor),
+		lockout.WithCountRefused(false),
+	)
+}
+
+// NewIPLimiter throttles login attempts per client IP, more leniently than
+// per account since clients may share an address.
+func NewIPLimiter(store lockout.Store, maxFailures int, lockoutFor time.Duration) *lockout.Limiter {
+	return lockout.New(store, "ip:",
+		lockout.WithFreeAttempts(10),
+		lockout.WithBackoff(time.Second, time.Minute),
+		lockout.WithLockout(maxFailures, lockoutFor),
+	)
+}
+
+// loginAttempt is a login attempt counted against the account and the
+// client before the password is checked.
+type loginAttempt struct {
+	email, ip       string
+	account, client lockout.Result
+}
+
+// reserveAttempt counts the attempt up front, so that concurrent guesses
+// cannot all pass the throttle before the first failure is recorded. It
+// returns a LockedError while the account or the client has to wait. A
+// refused attempt counts against the client only.
+func (s *Service) reserveAttempt(ctx context.Context, email, ip string) (*loginAttempt, error) {
+	a := &loginAttempt{email: email, ip: ip}
+	var err error
+	if a.account, err = s.accountLimiter.Attempt(ctx, email); err != nil {
+		return nil, err
+	}
+	if a.account.Wait > 0 {
+		if ip != "" {
+			if _, err := s.ipLimiter.Attempt(ctx, ip); err != nil {
+				log.Printf("login throttle for %s: %v", ip, err)
+			}
+		}
+		return nil, &LockedError{RetryAfter: a.account.Wait}
+	}
+	if ip == "" {
+		return a, nil
+	}
+	if a.client, err = s.ipLimiter.Attempt(ctx, ip); err != nil {
+		s.release(ctx, s.accountLimiter, email)
+		return nil, err
+	}
+	if a.client.Wait > 0 {
+		s.release(ctx, s.accountLimiter, email)
+		return nil, &LockedError{RetryAfter: a.client.Wait}
+	}
+	return a, nil
+}
+
+// failed records an audit event for each lockout caused by the failed
+// attempt a.
+func (s *Service) failed(ctx context.Context, a *loginAttempt, userID *uint) {
+	if a.account.Locked {
+		s.audit(ctx, AuditEvent{Type: AuditLockoutAccount, UserID: userID, Email: &a.email, IP: optional(a.ip), Until: &a.account.BlockedUntil})
+	}
+	if a.client.Locked {
+		s.audit(ctx, AuditEvent{Type: AuditLockoutIP, Email: &a.email, IP: &a.ip, Until: &a.client.BlockedUntil})
+	}
+}
+
+// succeeded forgets the failures of the account and takes back the attempt
+// of the client. The client's earlier failures stay, so that one valid
+// account does not unlock guessing at others.
+func (s *Service) succeeded(ctx context.Context, a *loginAttempt) {
+	if err := s.accountLimiter.Reset(ctx, a.email); err != nil {
+		log.Printf("login throttle reset for %s: %v", a.email, err)
+	}
+	if a.ip != "" {
+		s.release(ctx, s.ipLimiter, a.ip)
+	}
+}
+
+// cancel takes back attempt a when the credentials could not be checked.
+func (s *Service) cancel(ctx context.Context, a *loginAttempt) {
+	s.release(ctx, s.accountLimiter, a.email)
+	if a.ip != "" {
+		s.release(ctx, s.ipLimiter, a.ip)
+	}
+}
+
+// release only logs errors of the store; the attempt then stays counted.
+func (s *Service) release(ctx context.Context, l *lockout.Limiter, key string) {
+	if err := l.Release(ctx, key); err != nil {
+		log.Printf("login throttle release for %s: %v", key, err)
+	}
+}
+
+func (s *Service) audit(ctx context.Context, e AuditEvent) {
+	log.Printf("auth audit: %s email=%s ip=%s until=%s", e.Type, deref(e.Email), deref(e.IP), e.Until.UTC().Format(time.RFC3339))
+	if err := s.db.WithContext(ctx).Create(&e).Error; err != nil {
+		log.Printf("auth audit %s: %v", e.Type, err)
+	}
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
+}
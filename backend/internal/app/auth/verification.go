@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"appliedTo/internal/app/user"
+	"appliedTo/internal/platform/notify"
+	"appliedTo/internal/platform/validate"
+)
+
+var (
+	ErrInvalidToken     = errors.New("invalid or expired token")
+	ErrEmailNotVerified = errors.New("email not verified")
+)
+
+const (
+	defaultVerifyTTL = 48 * time.Hour
+	defaultResetTTL  = time.Hour
+
+	// mailTimeout bounds the delivery of one mail.
+	mailTimeout = time.Minute
+	// maxPendingMails bounds the mails in delivery at once; further ones
+	// are dropped until a slot frees up.
+	maxPendingMails = 16
+)
+
+// VerifyEmail uses up a verification token and marks its user's email
+// verified.
+func (s *Service) VerifyEmail(ctx context.Context, raw string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		t, err := consumeToken(tx, PurposeVerifyEmail, raw)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&user.User{}).
+			Where("id = ? AND email_verified_at IS NULL", t.UserID).
+			Update("email_verified_at", time.Now()).Error
+	})
+}
+
+// ResendVerification mails a new verification link to the account with the
+// given email. Unknown and already verified addresses are ignored, so the
+// result does not reveal whether an account exists.
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	u, ok, err := s.findByEmail(ctx, email)
+	if err != nil || !ok || u.EmailVerifiedAt != nil {
+		return err
+	}
+	return s.sendVerification(ctx, u.ID, u.Email)
+}
+
+// ForgotPassword mails a password reset link to the account with the given
+// email. Unknown addresses are ignored, so the result does not reveal
+// whether an account exists.
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	u, ok, err := s.findByEmail(ctx, email)
+	if err != nil || !ok {
+		return err
+	}
+
+	raw, err := issueToken(s.db.WithContext(ctx), u.ID, u.Email, PurposeResetPassword, s.resetTTL)
+	if err != nil {
+		return err
+	}
+	s.send(ctx, notify.Message{
+		To:      u.Email,
+		Subject: "Reset your AppliedTo password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your AppliedTo account.\n\n%s\n\n"+
+			"The link works once and expires in %s. If it was not you, ignore this mail; your password stays unchanged.",
+			s.link("/reset-password", raw), s.resetTTL),
+		Secrets: []string{raw},
+	})
+	return nil
+}
+
+// ResetPassword uses up a reset token and sets a new password. Since the
+// token proves access to the mailbox, the email counts as verified. Every
+// login of the user is ended.
+func (s *Service) ResetPassword(ctx context.Context, in PasswordResetRequest) error {
+	if err := validate.Required(validate.Field{Name: "password", Value: in.Password}); err != nil {
+		return err
+	}
+	hash, err := s.hasher.Hash(in.Password)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		t, err := consumeToken(tx, PurposeResetPassword, in.Token)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := tx.Model(&user.User{}).Where("id = ?", t.UserID).Updates(map[string]any{
+			"password":          hash,
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", now),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", t.UserID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// -------- helpers --------
+
+func (s *Service) sendVerification(ctx context.Context, userID uint, email string) error {
+	raw, err := issueToken(s.db.WithContext(ctx), userID, email, PurposeVerifyEmail, s.verifyTTL)
+	if err != nil {
+		return err
+	}
+	s.send(ctx, notify.Message{
+		To:      email,
+		Subject: "Verify your AppliedTo email address",
+		Body: fmt.Sprintf("Please confirm that this is your email address:\n\n%s\n\nThe link expires in %s.",
+			s.link("/verify-email", raw), s.verifyTTL),
+		Secrets: []string{raw},
+	})
+	return nil
+}
+
+// send mails msg in the background, so that response times do not tell
+// whether an account exists. Failures, and mail dropped while
+// maxPendingMails are in delivery, are only logged.
+func (s *Service) send(ctx context.Context, msg notify.Message) {
+	select {
+	case s.mailSlots <- struct{}{}:
+	default:
+		log.Printf("mail %q to %s: dropped, too many mails in delivery", msg.Subject, msg.To)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mailTimeout)
+	go func() {
+		defer func() { <-s.mailSlots }()
+		defer cancel()
+		if err := s.mailer.Notify(ctx, msg); err != nil {
+			log.Printf("mail %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
+
+// link points to a page of the web app that takes the token, or is the bare
+// token when no app URL is configured.
+func (s *Service) link(path, raw string) string {
+	if s.appURL == "" {
+		return "Token: " + raw
+	}
+	return s.appURL + path + "?token=" + url.QueryEscape(raw)
+}
+
+func (s *Service) findByEmail(ctx context.Context, email string) (user.User, bool, error) {
+	normalizedEmail, err := validate.NormalizeAndValidateEmail(email)
+	if err != nil {
+		return user.User{}, false, nil
+	}
+	var u user.User
+	err = s.db.WithContext(ctx).Where("email = ?", normalizedEmail).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user.User{}, false, nil
+	}
+	return u, err == nil, err
+}
+
+// issueToken stores a new token for the user and purpose, discarding the
+// unused ones, and returns it in plain.
+func issueToken(db *gorm.DB, userID uint, email string, purpose TokenPurpose, ttl time.Duration) (string, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	t := OneTimeToken{
+		UserID:    userID,
+		Email:     email,
+		Purpose:   purpose,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Delete(&OneTimeToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&t).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// consumeToken marks the token used and returns it. Unknown, used and
+// expired tokens, and tokens mailed to an address the user no longer has,
+// are ErrInvalidToken.
+func consumeToken(tx *gorm.DB, purpose TokenPurpose, raw string) (OneTimeToken, error) {
+	var t OneTimeToken
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", hashToken(raw), purpose).
+		First(&t).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return OneTimeToken{}, ErrInvalidToken
+	}
+	if err != nil {
+		return OneTimeToken{}, err
+	}
+
+	now := time.Now()
+	if t.UsedAt != nil || !now.Before(t.ExpiresAt) {
+		return OneTimeToken{}, ErrInvalidToken
+	}
+	var u user.User
+	if err := tx.Select("id", "email").First(&u, t.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return OneTimeToken{}, ErrInvalidToken
+		}
+		return OneTimeToken{}, err
+	}
+	if u.Email != t.Email {
+		return OneTimeToken{}, ErrInvalidToken
+	}
+
+	if err := tx.Model(&t).Update("used_at", now).Error; err != nil {
+		return OneTimeToken{}, err
+	}
+	return t, nil
+}
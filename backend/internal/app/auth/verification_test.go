@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"appliedTo/internal/app/user"
+	"appliedTo/internal/platform/db/dbtest"
+	"appliedTo/internal/platform/notify"
+	"appliedTo/internal/platform/security/password"
+	"appliedTo/internal/platform/security/token"
+)
+
+const testPassword = "correct horse battery"
+
+func newTestService(t *testing.T, opts ...Option) (*Service, *gorm.DB, *notify.MemoryNotifier) {
+	t.Helper()
+	db := dbtest.New(t)
+	hasher := password.NewBcrypt(password.WithCost(4))
+	mailer := notify.NewMemory()
+	jwt := &token.JWT{Secret: []byte("test"), Issuer: "appliedTo", AccessTTL: time.Minute}
+	opts = append([]Option{WithMailer(mailer)}, opts...)
+	return NewService(db, hasher, jwt, user.NewService(db, hasher), opts...), db, mailer
+}
+
+func register(t *testing.T, s *Service, email string) RegisterResponse {
+	t.Helper()
+	res, err := s.Register(context.Background(), RegisterRequest{
+		FirstName: "Jane", LastName: "Doe", Email: email, Password: testPassword,
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return res
+}
+
+var tokenRe = regexp.MustCompile(`Token: (\S+)`)
+
+// mailedToken waits for the next mail to email, which is sent in the
+// background, and returns the token it carries.
+func mailedToken(t *testing.T, mailer *notify.MemoryNotifier, email, subject string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, msg := range mailer.Messages() {
+			if msg.To != email || !strings.Contains(msg.Subject, subject) {
+				continue
+			}
+			m := tokenRe.FindStringSubmatch(msg.Body)
+			if m == nil {
+				t.Fatalf("mail %q carries no token:\n%s", msg.Subject, msg.Body)
+			}
+			mailer.Reset()
+			return m[1]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("no %q mail to %s", subject, email)
+	return ""
+}
+
+func TestRegisterAndVerifyEmail(t *testing.T) {
+	s, db, mailer := newTestService(t)
+	ctx := context.Background()
+
+	res := register(t, s, "Jane@Example.com")
+	if res.TokenResponse == nil || res.VerificationRequired {
+		t.Fatalf("Register() = %+v, want tokens", res)
+	}
+	raw := mailedToken(t, mailer, "jane@example.com", "Verify")
+
+	if err := s.VerifyEmail(ctx, "not-a-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("VerifyEmail(unknown) error = %v, want ErrInvalidToken", err)
+	}
+	if err := s.VerifyEmail(ctx, raw); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	var u user.User
+	if err := db.Where("email = ?", "jane@example.com").Take(&u).Error; err != nil {
+		t.Fatal(err)
+	}
+	if u.EmailVerifiedAt == nil {
+		t.Error("email not marked verified")
+	}
+	if err := s.VerifyEmail(ctx, raw); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("VerifyEmail() a second time error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	s, _, mailer := newTestService(t, WithRequireVerifiedEmail(true))
+	ctx := context.Background()
+
+	res := register(t, s, "jane@example.com")
+	if res.TokenResponse != nil || !res.VerificationRequired {
+		t.Fatalf("Register() = %+v, want verification required", res)
+	}
+	if _, err := s.Authenticate(ctx, "jane@example.com", testPassword, ""); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("Authenticate() before verification error = %v, want ErrEmailNotVerified", err)
+	}
+	if _, err := s.Authenticate(ctx, "jane@example.com", "wrong", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() with a wrong password error = %v, want ErrInvalidCredentials", err)
+	}
+
+	if err := s.VerifyEmail(ctx, mailedToken(t, mailer, "jane@example.com", "Verify")); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if _, err := s.Authenticate(ctx, "jane@example.com", testPassword, ""); err != nil {
+		t.Errorf("Authenticate() after verification error = %v", err)
+	}
+}
+
+func TestForgotAndResetPassword(t *testing.T) {
+	s, db, mailer := newTestService(t, WithRequireVerifiedEmail(true))
+	ctx := context.Background()
+	const newPassword = "tr0ub4dor&3"
+
+	register(t, s, "jane@example.com")
+	mailedToken(t, mailer, "jane@example.com", "Verify")
+
+	// Unknown addresses get no mail but the same answer.
+	if err := s.ForgotPassword(ctx, "nobody@example.com"); err != nil {
+		t.Fatalf("ForgotPassword(unknown) error = %v", err)
+	}
+
+	if err := s.ForgotPassword(ctx, "jane@example.com"); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	expired := mailedToken(t, mailer, "jane@example.com", "Reset")
+	if err := db.Model(&OneTimeToken{}).Where("token_hash = ?", hashToken(expired)).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ResetPassword(ctx, PasswordResetRequest{Token: expired, Password: newPassword}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ResetPassword(expired) error = %v, want ErrInvalidToken", err)
+	}
+
+	// Log in on another device first; resetting must end that login.
+	if err := s.ForgotPassword(ctx, "jane@example.com"); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	raw := mailedToken(t, mailer, "jane@example.com", "Reset")
+	var u user.User
+	if err := db.Where("email = ?", "jane@example.com").Take(&u).Error; err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := s.login(ctx, u.ID, u.Email, u.Role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ResetPassword(ctx, PasswordResetRequest{Token: raw, Password: newPassword}); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if err := s.ResetPassword(ctx, PasswordResetRequest{Token: raw, Password: "again"}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ResetPassword() a second time error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := s.Refresh(ctx, tokens.RefreshToken); err == nil {
+		t.Error("Refresh() with a token from before the reset succeeded")
+	}
+
+	if _, err := s.Authenticate(ctx, "jane@example.com", testPassword, ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() with the old password error = %v, want ErrInvalidCredentials", err)
+	}
+	// The reset proved access to the mailbox, so login no longer waits for
+	// verification.
+	if _, err := s.Authenticate(ctx, "jane@example.com", newPassword, ""); err != nil {
+		t.Errorf("Authenticate() with the new password error = %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package companyapi
+
+import (
+	"appliedTo/internal/app/company"
+	"appliedTo/internal/app/jobapplication"
+	"appliedTo/internal/platform/http/middleware"
+	"appliedTo/internal/platform/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handlers struct {
+	Svc          *company.Service
+	Applications *jobapplication.Service
+}
+
+func NewHandlers(s *company.Service, applications *jobapplication.Service) *Handlers {
+	return &Handlers{Svc: s, Applications: applications}
+}
+
+// @Summary Create a company
+// @Description Creates a company. Names are unique per user after normalization, so "ACME Inc." conflicts with an existing "Acme".
+// @Tags company
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   company  body  company.CompanyCreateDto  true  "Company data"
+// @Success 201 {object} map[string]company.CompanyPublicDto "Created company"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 409 {object} problem.Problem "Company already exists"
+// @Failure 500 {object} problem.Problem "Could not create company"
+// @Router /companies [post]
+func (h *Handlers) CreateCompany(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var in company.CompanyCreateDto
+	if err := c.ShouldBindJSON(&in); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.Create(c.Request.Context(), userID, in)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"company": out})
+}
+
+// @Summary List companies
+// @Description Lists the caller's companies by name, each with the number of its applications.
+// @Tags company
+// @Security BearerAuth
+// @Produce  json
+// @Success 200 {object} map[string][]company.CompanyPublicDto "Companies"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /companies [get]
+func (h *Handlers) ListCompanies(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.Svc.List(c.Request.Context(), userID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"companies": out})
+}
+
+// @Summary Get a company with its applications
+// @Description Returns the company and a page of its job applications. The applications accept the query parameters of the job application list.
+// @Tags company
+// @Security BearerAuth
+// @Produce  json
+// @Param   id      path   int     true   "Company ID"
+// @Param   q       query  string  false  "Full-text search over the applications"
+// @Param   sort    query  string  false  "Sort field of the applications, prefix with - for descending"  default(-id)
+// @Param   limit   query  int     false  "Page size (max 100)"  default(20)
+// @Param   cursor  query  string  false  "Cursor from a previous page"
+// @Success 200 {object} map[string]interface{} "Company and job applications"
+// @Failure 400 {object} problem.Problem "Invalid query"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Company not found"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /companies/{id} [get]
+func (h *Handlers) GetCompany(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyCompanyID)
+
+	var q jobapplication.JobApplicationListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+	q.CompanyID = []uint{id}
+
+	out, err := h.Svc.GetByID(c.Request.Context(), userID, id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	apps, err := h.Applications.List(c.Request.Context(), userID, q)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"company": out, "job_applications": apps})
+}
+
+// @Summary Update a company (full replace)
+// @Description Replaces the company. A new name is copied onto its applications.
+// @Tags company
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   id       path  int                       true  "Company ID"
+// @Param   company  body  company.CompanyCreateDto  true  "Company data"
+// @Success 200 {object} map[string]company.CompanyPublicDto "Updated company"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Company not found"
+// @Failure 409 {object} problem.Problem "Company already exists"
+// @Failure 500 {object} problem.Problem "Update failed"
+// @Router /companies/{id} [put]
+func (h *Handlers) UpdateCompany(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyCompanyID)
+
+	var in company.CompanyCreateDto
+	if err := c.ShouldBindJSON(&in); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.Update(c.Request.Context(), userID, id, in)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"company": out})
+}
+
+// @Summary Patch a company
+// @Description Partially update a company. Only fields provided in the body will be modified.
+// @Tags company
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   id       path  int                      true  "Company ID"
+// @Param   company  body  company.CompanyPatchDto  true  "Fields to patch"
+// @Success 200 {object} map[string]company.CompanyPublicDto "Updated company"
+// @Failure 400 {object} problem.Problem "Invalid payload"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Company not found"
+// @Failure 409 {object} problem.Problem "Company already exists"
+// @Failure 500 {object} problem.Problem "Update failed"
+// @Router /companies/{id} [patch]
+func (h *Handlers) PatchCompany(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyCompanyID)
+
+	var patch company.CompanyPatchDto
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.Patch(c.Request.Context(), userID, id, patch)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"company": out})
+}
+
+// @Summary Delete a company
+// @Description Deletes the company. Its applications keep the company name but are no longer linked.
+// @Tags company
+// @Security BearerAuth
+// @Produce  json
+// @Param   id  path  int  true  "Company ID"
+// @Success 200 {object} map[string]string "Company deleted."
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Company not found"
+// @Failure 500 {object} problem.Problem "Could not delete company"
+// @Router /companies/{id} [delete]
+func (h *Handlers) DeleteCompany(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyCompanyID)
+
+	if err := h.Svc.Delete(c.Request.Context(), userID, id); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Company deleted successfully"})
+}
@@ -0,0 +1,23 @@
+package companyapi
+
+import (
+	"appliedTo/internal/platform/http/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCompanyRoutes(h *Handlers, requireAuth, requireID gin.HandlerFunc) routes.RouteConfig {
+	return routes.RouteConfig{
+		Prefix: "/companies",
+		Use:    []gin.HandlerFunc{requireAuth},
+		Register: func(g *gin.RouterGroup) {
+			g.GET("", h.ListCompanies)
+			g.POST("", h.CreateCompany)
+			withID := g.Group("/:id", requireID)
+			withID.GET("", h.GetCompany)
+			withID.PUT("", h.UpdateCompany)
+			withID.PATCH("", h.PatchCompany)
+			withID.DELETE("", h.DeleteCompany)
+		},
+	}
+}
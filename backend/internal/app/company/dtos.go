@@ -0,0 +1,31 @@
+package company
+
+import "time"
+
+type CompanyCreateDto struct {
+	Name string `json:"name"`
+	// Domain may be given as a bare domain or as a URL.
+	Domain   *string `json:"domain,omitempty"`
+	Industry *string `json:"industry,omitempty"`
+	Size     *string `json:"size,omitempty"`
+	Notes    *string `json:"notes,omitempty"`
+}
+
+type CompanyPatchDto struct {
+	Name     *string `json:"name,omitempty"`
+	Domain   *string `json:"domain,omitempty"`
+	Industry *string `json:"industry,omitempty"`
+	Size     *string `json:"size,omitempty"`
+	Notes    *string `json:"notes,omitempty"`
+}
+
+type CompanyPublicDto struct {
+	ID               uint      `json:"id"`
+	Name             string    `json:"name"`
+	Domain           *string   `json:"domain,omitempty"`
+	Industry         *string   `json:"industry,omitempty"`
+	Size             *string   `json:"size,omitempty"`
+	Notes            *string   `json:"notes,omitempty"`
+	ApplicationCount int64     `json:"applicationCount"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
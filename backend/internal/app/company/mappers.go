@@ -0,0 +1,62 @@
+package company
+
+import (
+	"strings"
+
+	"appliedTo/internal/platform/patch"
+)
+
+// --- INPUT MAPPERS ---
+
+func OverwriteModel(m *Company, dto CompanyCreateDto) {
+	m.Name     = strings.TrimSpace(dto.Name)
+	m.Domain   = Domain(dto.Domain)
+	m.Industry = dto.Industry
+	m.Size     = toSize(dto.Size)
+	m.Notes    = dto.Notes
+
+	m.NormalizedName = Normalize(m.Name)
+}
+
+func PatchModel(m *Company, dto CompanyPatchDto) {
+	if dto.Name != nil {
+		m.Name = strings.TrimSpace(*dto.Name)
+		m.NormalizedName = Normalize(m.Name)
+	}
+	if dto.Domain != nil {
+		m.Domain = Domain(dto.Domain)
+	}
+	patch.PatchRef(&m.Industry, dto.Industry)
+	if dto.Size != nil {
+		m.Size = toSize(dto.Size)
+	}
+	patch.PatchRef(&m.Notes, dto.Notes)
+}
+
+func toSize(s *string) *CompanySize {
+	if s == nil || *s == "" {
+		return nil
+	}
+	size := CompanySize(*s)
+	return &size
+}
+
+// --- OUTPUT MAPPER ---
+
+func MapModelToPublicDto(m Company, applications int64) CompanyPublicDto {
+	var size *string
+	if m.Size != nil {
+		s := string(*m.Size)
+		size = &s
+	}
+	return CompanyPublicDto{
+		ID:               m.ID,
+		Name:             m.Name,
+		Domain:           m.Domain,
+		Industry:         m.Industry,
+		Size:             size,
+		Notes:            m.Notes,
+		ApplicationCount: applications,
+		CreatedAt:        m.CreatedAt.UTC(),
+	}
+}
@@ -0,0 +1,36 @@
+package company
+
+import "time"
+
+// Company is an employer as tracked by one user. Applications reference it
+// through JobApplication.CompanyID; NormalizedName (see Normalize) is unique
+// per user so spelling variants resolve to the same company.
+type Company struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	UserID         uint         `json:"-" gorm:"not null;uniqueIndex:uniq_companies_user_name"`
+	Name           string       `json:"name" gorm:"not null"`
+	NormalizedName string       `json:"-" gorm:"not null;uniqueIndex:uniq_companies_user_name"`
+	Domain         *string      `json:"domain,omitempty" gorm:"index"`
+	Industry       *string      `json:"industry,omitempty"`
+	Size           *CompanySize `json:"size,omitempty" gorm:"type:VARCHAR(16)"`
+	Notes          *string      `json:"notes,omitempty"`
+	CreatedAt      time.Time    `json:"createdAt"`
+	UpdatedAt      time.Time    `json:"updatedAt"`
+}
+
+// CompanySize is a head count bracket.
+type CompanySize string
+const (
+	Size1To10      CompanySize = "1-10"
+	Size11To50     CompanySize = "11-50"
+	Size51To200    CompanySize = "51-200"
+	Size201To500   CompanySize = "201-500"
+	Size501To1000  CompanySize = "501-1000"
+	Size1001To5000 CompanySize = "1001-5000"
+	Size5001Plus   CompanySize = "5001+"
+)
+
+var CompanySizes = []CompanySize{
+	Size1To10, Size11To50, Size51To200, Size201To500,
+	Size501To1000, Size1001To5000, Size5001Plus,
+}
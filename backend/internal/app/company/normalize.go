@@ -0,0 +1,84 @@
+package company
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// legalSuffixes are dropped from the end of company names so that "ACME Inc."
+// and "Acme" normalize alike. Migration 0006_companies uses the same list.
+var legalSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "ltd": true, "limited": true,
+	"gmbh": true, "ag": true, "corp": true, "corporation": true, "co": true,
+	"company": true, "plc": true, "sa": true, "bv": true, "srl": true, "kg": true,
+}
+
+var nonAlnum = regexp.MustCompile(`[^\pL\pN]+`)
+
+// Normalize returns the key company names are de-duplicated by: lower case,
+// punctuation and legal-form suffixes removed, words separated by single
+// spaces. A name that consists only of a suffix ("Company") is kept. It is
+// empty if name holds no letters or digits.
+func Normalize(name string) string {
+	words := strings.Fields(nonAlnum.ReplaceAllString(strings.ToLower(name), " "))
+	end := len(words)
+	for end > 1 && legalSuffixes[words[end-1]] {
+		end--
+	}
+	return strings.Join(words[:end], " ")
+}
+
+// Domain extracts the host of a company URL or bare domain, lower-cased and
+// without a leading "www.". It is nil if raw has no host.
+func Domain(raw *string) *string {
+	if raw == nil {
+		return nil
+	}
+	s := strings.TrimSpace(*raw)
+	if s == "" {
+		return nil
+	}
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	return &host
+}
+
+// sharedHosts serve pages of many companies: job boards, applicant tracking
+// systems and social networks. A company URL on one of them, or on a
+// subdomain, says nothing about which company it is. Migration 0006_companies
+// uses the same list.
+var sharedHosts = []string{
+	"linkedin.com", "xing.com", "indeed.com", "glassdoor.com", "stepstone.de",
+	"wellfound.com", "angel.co", "github.com", "google.com",
+	"greenhouse.io", "lever.co", "myworkdayjobs.com", "workday.com",
+	"smartrecruiters.com", "ashbyhq.com", "workable.com", "recruitee.com",
+	"personio.de", "personio.com", "teamtailor.com", "jobvite.com",
+	"icims.com", "bamboohr.com", "breezy.hr", "join.com",
+}
+
+// isSharedHost reports whether host is or is under one of sharedHosts.
+func isSharedHost(host string) bool {
+	for _, h := range sharedHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// similarNames reports whether two normalized names may name the same
+// company: they are equal, or the words of one start the other ("acme" and
+// "acme robotics").
+func similarNames(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return a == b || strings.HasPrefix(b, a+" ")
+}
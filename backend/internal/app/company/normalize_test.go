@@ -0,0 +1,43 @@
+package company
+
+import "testing"
+
+func TestIsSharedHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"linkedin.com", true},
+		{"de.linkedin.com", true},
+		{"boards.greenhouse.io", true},
+		{"jobs.lever.co", true},
+		{"acme.wd5.myworkdayjobs.com", true},
+		{"acme.com", false},
+		{"careers.acme.com", false},
+		{"notlinkedin.com", false},
+	}
+	for _, tt := range tests {
+		if got := isSharedHost(tt.host); got != tt.want {
+			t.Errorf("isSharedHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarNames(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"acme", "acme", true},
+		{"acme", "acme robotics", true},
+		{"acme robotics", "acme", true},
+		{"acme", "acmes", false},
+		{"globex", "acme", false},
+		{"robotics", "acme robotics", false},
+	}
+	for _, tt := range tests {
+		if got := similarNames(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarNames(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
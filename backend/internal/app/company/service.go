@@ -0,0 +1,194 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"appliedTo/internal/platform/validate"
+)
+
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// owned scopes a query to the companies of the given user.
+func (s *Service) owned(ctx context.Context, userID uint) *gorm.DB {
+	return s.db.WithContext(ctx).Where("user_id = ?", userID)
+}
+
+// companyRow is a company with the number of its (not deleted) applications.
+type companyRow struct {
+	Company
+	ApplicationCount int64
+}
+
+const applicationCount = `(SELECT count(*) FROM job_applications ja
+	WHERE ja.company_id = companies.id AND ja.deleted_at IS NULL) AS application_count`
+
+// CREATE
+func (s *Service) Create(ctx context.Context, userID uint, in CompanyCreateDto) (CompanyPublicDto, error) {
+	var m Company
+	OverwriteModel(&m, in)
+	m.UserID = userID
+	if err := validateModel(&m); err != nil {
+		return CompanyPublicDto{}, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(&m).Error; err != nil {
+		return CompanyPublicDto{}, err
+	}
+	return MapModelToPublicDto(m, 0), nil
+}
+
+// LIST
+func (s *Service) List(ctx context.Context, userID uint) ([]CompanyPublicDto, error) {
+	var rows []companyRow
+	if err := s.owned(ctx, userID).Model(&Company{}).
+		Select("companies.*, " + applicationCount).
+		Order("normalized_name, id").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	out := make([]CompanyPublicDto, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, MapModelToPublicDto(r.Company, r.ApplicationCount))
+	}
+	return out, nil
+}
+
+// READ
+func (s *Service) GetByID(ctx context.Context, userID, id uint) (CompanyPublicDto, error) {
+	var r companyRow
+	if err := s.owned(ctx, userID).Model(&Company{}).
+		Select("companies.*, "+applicationCount).
+		First(&r, id).Error; err != nil {
+		return CompanyPublicDto{}, err
+	}
+	return MapModelToPublicDto(r.Company, r.ApplicationCount), nil
+}
+
+// UPDATE (full replace)
+func (s *Service) Update(ctx context.Context, userID, id uint, in CompanyCreateDto) (CompanyPublicDto, error) {
+	return s.modify(ctx, userID, id, func(m *Company) { OverwriteModel(m, in) })
+}
+
+// PATCH (partial update)
+func (s *Service) Patch(ctx context.Context, userID, id uint, patch CompanyPatchDto) (CompanyPublicDto, error) {
+	return s.modify(ctx, userID, id, func(m *Company) { PatchModel(m, patch) })
+}
+
+// DELETE
+// Applications of a deleted company keep its name but lose the reference.
+func (s *Service) Delete(ctx context.Context, userID, id uint) error {
+	tx := s.owned(ctx, userID).Delete(&Company{}, id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// -------- helpers --------
+
+// modify applies change to the user's company id. A rename is copied onto
+// the company's applications, which keep the name for display and search.
+func (s *Service) modify(ctx context.Context, userID, id uint, change func(m *Company)) (CompanyPublicDto, error) {
+	var r companyRow
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		m := &r.Company
+		if err := tx.Where("user_id = ?", userID).First(m, id).Error; err != nil {
+			return err
+		}
+		name := m.Name
+		change(m)
+		m.UserID, m.ID = userID, id
+		if err := validateModel(m); err != nil {
+			return err
+		}
+		if err := tx.Save(m).Error; err != nil {
+			return err
+		}
+		if m.Name != name {
+			if err := tx.Table("job_applications").Where("company_id = ?", id).
+				Update("company", m.Name).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Table("job_applications").
+			Where("company_id = ? AND deleted_at IS NULL", id).
+			Count(&r.ApplicationCount).Error
+	})
+	if err != nil {
+		return CompanyPublicDto{}, err
+	}
+	return MapModelToPublicDto(r.Company, r.ApplicationCount), nil
+}
+
+// Resolve returns the user's company for an application that names it: the
+// company with the same normalized name, else the oldest one whose domain
+// matches companyURL and whose name is similar, else a new company. Company
+// URLs on job boards and other shared hosts are not matched by domain. It
+// runs in tx so that callers can link the application in the same
+// transaction.
+func Resolve(tx *gorm.DB, userID uint, name string, companyURL *string) (Company, error) {
+	name = strings.TrimSpace(name)
+	norm := Normalize(name)
+	if norm == "" {
+		return Company{}, validate.Errors{{Field: "company", Message: "must contain a letter or digit"}}
+	}
+
+	var m Company
+	err := tx.Where("user_id = ? AND normalized_name = ?", userID, norm).Take(&m).Error
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return m, err
+	}
+
+	domain := Domain(companyURL)
+	if domain != nil && isSharedHost(*domain) {
+		domain = nil
+	}
+	if domain != nil {
+		var candidates []Company
+		if err := tx.Where("user_id = ? AND domain = ?", userID, *domain).Order("id").
+			Find(&candidates).Error; err != nil {
+			return Company{}, err
+		}
+		for _, c := range candidates {
+			if similarNames(c.NormalizedName, norm) {
+				return c, nil
+			}
+		}
+	}
+
+	// A concurrent request may create the same company; let the unique key
+	// decide and read whichever row won.
+	m = Company{UserID: userID, Name: name, NormalizedName: norm, Domain: domain}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "normalized_name"}},
+		DoNothing: true,
+	}).Create(&m).Error; err != nil {
+		return Company{}, err
+	}
+	if m.ID == 0 {
+		err = tx.Where("user_id = ? AND normalized_name = ?", userID, norm).Take(&m).Error
+	}
+	return m, err
+}
+
+// Owned loads the user's company id within tx.
+func Owned(tx *gorm.DB, userID, id uint) (Company, error) {
+	var m Company
+	err := tx.Where("user_id = ?", userID).Take(&m, id).Error
+	return m, err
+}
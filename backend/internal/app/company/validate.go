@@ -0,0 +1,17 @@
+package company
+
+import "appliedTo/internal/platform/validate"
+
+func validateModel(m *Company) error {
+	var errs validate.Errors
+
+	errs.Required(validate.Field{Name: "name", Value: m.Name})
+	if m.Name != "" && m.NormalizedName == "" {
+		errs.Add("name", "must contain a letter or digit")
+	}
+	if m.Size != nil {
+		validate.OneOf(&errs, "size", *m.Size, CompanySizes...)
+	}
+
+	return errs.Err()
+}
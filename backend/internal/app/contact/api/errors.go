@@ -0,0 +1,12 @@
+package contactapi
+
+import (
+	"net/http"
+
+	"appliedTo/internal/app/contact"
+	"appliedTo/internal/platform/http/problem"
+)
+
+func init() {
+	problem.Register(contact.ErrContactExists, http.StatusConflict, "contact_exists", "A contact with this email already exists")
+}
@@ -0,0 +1,203 @@
+package contactapi
+
+import (
+	"appliedTo/internal/app/contact"
+	"appliedTo/internal/platform/http/middleware"
+	"appliedTo/internal/platform/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handlers struct {
+	Svc *contact.Service
+}
+
+func NewHandlers(s *contact.Service) *Handlers { return &Handlers{Svc: s} }
+
+// @Summary Create a contact
+// @Description Adds a contact to the caller's address book, optionally linked to job applications and companies. Email addresses are normalized and unique per user.
+// @Tags contact
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   contact  body  contact.ContactCreateDto  true  "Contact data"
+// @Success 201 {object} map[string]contact.ContactPublicDto "Created contact"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 409 {object} problem.Problem "Contact with this email exists"
+// @Failure 500 {object} problem.Problem "Could not create contact"
+// @Router /contacts [post]
+func (h *Handlers) CreateContact(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var in contact.ContactCreateDto
+	if err := c.ShouldBindJSON(&in); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.Create(c.Request.Context(), userID, in)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"contact": out})
+}
+
+// @Summary List contacts
+// @Description Lists the caller's contacts by name, optionally only those of one job application or company.
+// @Tags contact
+// @Security BearerAuth
+// @Produce  json
+// @Param   jobApplicationId  query  int     false  "Only contacts linked to this job application"
+// @Param   companyId         query  int     false  "Only contacts linked to this company"
+// @Param   q                 query  string  false  "Case-insensitive match on name, email and role"
+// @Success 200 {object} map[string][]contact.ContactPublicDto "Contacts"
+// @Failure 400 {object} problem.Problem "Invalid query"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /contacts [get]
+func (h *Handlers) ListContacts(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var f contact.ContactFilter
+	if err := c.ShouldBindQuery(&f); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.List(c.Request.Context(), userID, f)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contacts": out})
+}
+
+// @Summary Get a contact
+// @Tags contact
+// @Security BearerAuth
+// @Produce  json
+// @Param   id  path  int  true  "Contact ID"
+// @Success 200 {object} map[string]contact.ContactPublicDto "Contact"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Contact not found"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /contacts/{id} [get]
+func (h *Handlers) GetContact(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyContactID)
+
+	out, err := h.Svc.GetByID(c.Request.Context(), userID, id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contact": out})
+}
+
+// @Summary Update a contact (full replace)
+// @Description Replaces the contact, including its links to job applications and companies.
+// @Tags contact
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   id       path  int                       true  "Contact ID"
+// @Param   contact  body  contact.ContactCreateDto  true  "Contact data"
+// @Success 200 {object} map[string]contact.ContactPublicDto "Updated contact"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Contact not found"
+// @Failure 409 {object} problem.Problem "Contact with this email exists"
+// @Failure 500 {object} problem.Problem "Update failed"
+// @Router /contacts/{id} [put]
+func (h *Handlers) UpdateContact(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyContactID)
+
+	var in contact.ContactCreateDto
+	if err := c.ShouldBindJSON(&in); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.Update(c.Request.Context(), userID, id, in)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contact": out})
+}
+
+// @Summary Patch a contact
+// @Description Partially update a contact. Only fields provided in the body will be modified; link lists replace the current links.
+// @Tags contact
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   id       path  int                      true  "Contact ID"
+// @Param   contact  body  contact.ContactPatchDto  true  "Fields to patch"
+// @Success 200 {object} map[string]contact.ContactPublicDto "Updated contact"
+// @Failure 400 {object} problem.Problem "Invalid payload"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Contact not found"
+// @Failure 409 {object} problem.Problem "Contact with this email exists"
+// @Failure 500 {object} problem.Problem "Update failed"
+// @Router /contacts/{id} [patch]
+func (h *Handlers) PatchContact(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyContactID)
+
+	var patch contact.ContactPatchDto
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.Patch(c.Request.Context(), userID, id, patch)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"contact": out})
+}
+
+// @Summary Delete a contact
+// @Tags contact
+// @Security BearerAuth
+// @Produce  json
+// @Param   id  path  int  true  "Contact ID"
+// @Success 200 {object} map[string]string "Contact deleted."
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Contact not found"
+// @Failure 500 {object} problem.Problem "Could not delete contact"
+// @Router /contacts/{id} [delete]
+func (h *Handlers) DeleteContact(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyContactID)
+
+	if err := h.Svc.Delete(c.Request.Context(), userID, id); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Contact deleted successfully"})
+}
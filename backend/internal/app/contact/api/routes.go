@@ -0,0 +1,23 @@
+package contactapi
+
+import (
+	"appliedTo/internal/platform/http/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupContactRoutes(h *Handlers, requireAuth, requireID gin.HandlerFunc) routes.RouteConfig {
+	return routes.RouteConfig{
+		Prefix: "/contacts",
+		Use:    []gin.HandlerFunc{requireAuth},
+		Register: func(g *gin.RouterGroup) {
+			g.GET("", h.ListContacts)
+			g.POST("", h.CreateContact)
+			withID := g.Group("/:id", requireID)
+			withID.GET("", h.GetContact)
+			withID.PUT("", h.UpdateContact)
+			withID.PATCH("", h.PatchContact)
+			withID.DELETE("", h.DeleteContact)
+		},
+	}
+}
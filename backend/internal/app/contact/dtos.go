@@ -0,0 +1,47 @@
+package contact
+
+import "time"
+
+type BaseContactDto struct {
+	Name        string  `json:"name"`
+	Role        *string `json:"role,omitempty"`
+	Email       *string `json:"email,omitempty"`
+	Phone       *string `json:"phone,omitempty"`
+	LinkedInURL *string `json:"linkedinUrl,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+type ContactCreateDto struct {
+	BaseContactDto
+	JobApplicationIDs []uint `json:"jobApplicationIds,omitempty"`
+	CompanyIDs        []uint `json:"companyIds,omitempty"`
+}
+
+type ContactPatchDto struct {
+	Name        *string `json:"name,omitempty"`
+	Role        *string `json:"role,omitempty"`
+	Email       *string `json:"email,omitempty"`
+	Phone       *string `json:"phone,omitempty"`
+	LinkedInURL *string `json:"linkedinUrl,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+	// Link lists replace the current links when present.
+	JobApplicationIDs *[]uint `json:"jobApplicationIds,omitempty"`
+	CompanyIDs        *[]uint `json:"companyIds,omitempty"`
+}
+
+type ContactPublicDto struct {
+	ID uint `json:"id"`
+	BaseContactDto
+	JobApplicationIDs []uint    `json:"jobApplicationIds"`
+	CompanyIDs        []uint    `json:"companyIds"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// ContactFilter narrows a listing of contacts. Q matches name, email and
+// role case-insensitively.
+type ContactFilter struct {
+	JobApplicationID *uint  `form:"jobApplicationId"`
+	CompanyID        *uint  `form:"companyId"`
+	Q                string `form:"q"`
+}
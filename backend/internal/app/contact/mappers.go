@@ -0,0 +1,55 @@
+package contact
+
+import (
+	"strings"
+
+	"appliedTo/internal/platform/patch"
+)
+
+// --- INPUT MAPPERS ---
+
+func OverwriteModel(m *Contact, dto BaseContactDto) {
+	m.Name        = strings.TrimSpace(dto.Name)
+	m.Role        = dto.Role
+	m.Email       = dto.Email
+	m.Phone       = dto.Phone
+	m.LinkedInURL = dto.LinkedInURL
+	m.Notes       = dto.Notes
+}
+
+func PatchModel(m *Contact, dto ContactPatchDto) {
+	if dto.Name != nil {
+		m.Name = strings.TrimSpace(*dto.Name)
+	}
+	patch.PatchRef(&m.Role, dto.Role)
+	patch.PatchRef(&m.Email, dto.Email)
+	patch.PatchRef(&m.Phone, dto.Phone)
+	patch.PatchRef(&m.LinkedInURL, dto.LinkedInURL)
+	patch.PatchRef(&m.Notes, dto.Notes)
+}
+
+// --- OUTPUT MAPPER ---
+
+func MapModelToPublicDto(m Contact, l links) ContactPublicDto {
+	if l.applications == nil {
+		l.applications = []uint{}
+	}
+	if l.companies == nil {
+		l.companies = []uint{}
+	}
+	return ContactPublicDto{
+		ID: m.ID,
+		BaseContactDto: BaseContactDto{
+			Name:        m.Name,
+			Role:        m.Role,
+			Email:       m.Email,
+			Phone:       m.Phone,
+			LinkedInURL: m.LinkedInURL,
+			Notes:       m.Notes,
+		},
+		JobApplicationIDs: l.applications,
+		CompanyIDs:        l.companies,
+		CreatedAt:         m.CreatedAt.UTC(),
+		UpdatedAt:         m.UpdatedAt.UTC(),
+	}
+}
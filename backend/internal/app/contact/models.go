@@ -0,0 +1,24 @@
+package contact
+
+import "time"
+
+// Contact is a recruiter, hiring manager or other person a user deals with.
+// It is linked many-to-many with job applications and companies through the
+// job_application_contacts and company_contacts tables.
+type Contact struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"-" gorm:"not null;index"`
+	Name        string    `json:"name" gorm:"not null"`
+	Role        *string   `json:"role,omitempty"`
+	Email       *string   `json:"email,omitempty"`
+	Phone       *string   `json:"phone,omitempty"`
+	LinkedInURL *string   `json:"linkedinUrl,omitempty" gorm:"column:linkedin_url"`
+	Notes       *string   `json:"notes,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+const (
+	applicationLinks = "job_application_contacts"
+	companyLinks     = "company_contacts"
+)
@@ -0,0 +1,327 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"appliedTo/internal/platform/validate"
+)
+
+var ErrContactExists = errors.New("contact with this email already exists")
+
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// owned scopes a query to the contacts of the given user.
+func (s *Service) owned(ctx context.Context, userID uint) *gorm.DB {
+	return s.db.WithContext(ctx).Where("user_id = ?", userID)
+}
+
+// CREATE
+func (s *Service) Create(ctx context.Context, userID uint, in ContactCreateDto) (ContactPublicDto, error) {
+	var m Contact
+	OverwriteModel(&m, in.BaseContactDto)
+	m.UserID = userID
+	if err := normalizeAndValidate(&m); err != nil {
+		return ContactPublicDto{}, err
+	}
+
+	var l links
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+		l, err = setLinks(tx, userID, m.ID, &in.JobApplicationIDs, &in.CompanyIDs)
+		return err
+	})
+	if err != nil {
+		return ContactPublicDto{}, translate(err)
+	}
+	return MapModelToPublicDto(m, l), nil
+}
+
+// LIST
+func (s *Service) List(ctx context.Context, userID uint, f ContactFilter) ([]ContactPublicDto, error) {
+	q := s.owned(ctx, userID)
+	if f.JobApplicationID != nil {
+		q = q.Where("id IN (SELECT contact_id FROM "+applicationLinks+" WHERE job_application_id = ?)", *f.JobApplicationID)
+	}
+	if f.CompanyID != nil {
+		q = q.Where("id IN (SELECT contact_id FROM "+companyLinks+" WHERE company_id = ?)", *f.CompanyID)
+	}
+	if term := strings.TrimSpace(f.Q); term != "" {
+		like := "%" + escapeLike(term) + "%"
+		q = q.Where("(name ILIKE ? OR email ILIKE ? OR role ILIKE ?)", like, like, like)
+	}
+
+	var contacts []Contact
+	if err := q.Order("lower(name), id").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(contacts))
+	for _, c := range contacts {
+		ids = append(ids, c.ID)
+	}
+	all, err := loadLinks(s.db.WithContext(ctx), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]ContactPublicDto, 0, len(contacts))
+	for _, c := range contacts {
+		out = append(out, MapModelToPublicDto(c, all[c.ID]))
+	}
+	return out, nil
+}
+
+// READ
+func (s *Service) GetByID(ctx context.Context, userID, id uint) (ContactPublicDto, error) {
+	var m Contact
+	if err := s.owned(ctx, userID).First(&m, id).Error; err != nil {
+		return ContactPublicDto{}, err
+	}
+	all, err := loadLinks(s.db.WithContext(ctx), []uint{id})
+	if err != nil {
+		return ContactPublicDto{}, err
+	}
+	return MapModelToPublicDto(m, all[id]), nil
+}
+
+// UPDATE (full replace)
+func (s *Service) Update(ctx context.Context, userID, id uint, in ContactCreateDto) (ContactPublicDto, error) {
+	return s.modify(ctx, userID, id, func(m *Contact) { OverwriteModel(m, in.BaseContactDto) },
+		&in.JobApplicationIDs, &in.CompanyIDs)
+}
+
+// PATCH (partial update)
+func (s *Service) Patch(ctx context.Context, userID, id uint, patch ContactPatchDto) (ContactPublicDto, error) {
+	return s.modify(ctx, userID, id, func(m *Contact) { PatchModel(m, patch) },
+		patch.JobApplicationIDs, patch.CompanyIDs)
+}
+
+// DELETE
+func (s *Service) Delete(ctx context.Context, userID, id uint) error {
+	tx := s.owned(ctx, userID).Delete(&Contact{}, id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// -------- helpers --------
+
+func (s *Service) modify(ctx context.Context, userID, id uint, change func(m *Contact), applications, companies *[]uint) (ContactPublicDto, error) {
+	var (
+		m Contact
+		l links
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		if err := tx.Where("user_id = ?", userID).First(&m, id).Error; err != nil {
+			return err
+		}
+		change(&m)
+		m.UserID, m.ID = userID, id
+		if err := normalizeAndValidate(&m); err != nil {
+			return err
+		}
+		if err := tx.Save(&m).Error; err != nil {
+			return err
+		}
+		l, err = setLinks(tx, userID, id, applications, companies)
+		return err
+	})
+	if err != nil {
+		return ContactPublicDto{}, translate(err)
+	}
+	return MapModelToPublicDto(m, l), nil
+}
+
+func translate(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrContactExists
+	}
+	return err
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(s string) string { return likeEscaper.Replace(s) }
+
+// ---- links ----
+
+// links are the IDs of the applications and companies a contact is linked to.
+type links struct {
+	applications []uint
+	companies    []uint
+}
+
+// setLinks replaces the application and company links of a contact with the
+// given ones; a nil list leaves those links unchanged. Every ID must belong
+// to the user. It returns the resulting links.
+func setLinks(tx *gorm.DB, userID, contactID uint, applications, companies *[]uint) (links, error) {
+	var errs validate.Errors
+	if applications != nil {
+		if err := replaceLinks(tx, &errs, userID, contactID, *applications,
+			"jobApplicationIds", "job_applications", applicationLinks, "job_application_id"); err != nil {
+			return links{}, err
+		}
+	}
+	if companies != nil {
+		if err := replaceLinks(tx, &errs, userID, contactID, *companies,
+			"companyIds", "companies", companyLinks, "company_id"); err != nil {
+			return links{}, err
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return links{}, err
+	}
+
+	all, err := loadLinks(tx, []uint{contactID})
+	return all[contactID], err
+}
+
+func replaceLinks(tx *gorm.DB, errs *validate.Errors, userID, contactID uint, ids []uint, field, table, linkTable, column string) error {
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+	if len(ids) > 0 {
+		owned := tx.Table(table).Where("user_id = ? AND id IN ?", userID, ids)
+		if table == "job_applications" {
+			owned = owned.Where("deleted_at IS NULL")
+		}
+		var n int64
+		if err := owned.Count(&n).Error; err != nil {
+			return err
+		}
+		if int(n) != len(ids) {
+			errs.Add(field, "contains unknown IDs")
+			return nil
+		}
+	}
+
+	if err := tx.Table(linkTable).Where("contact_id = ?", contactID).Delete(nil).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	rows := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, map[string]any{column: id, "contact_id": contactID})
+	}
+	return tx.Table(linkTable).Create(rows).Error
+}
+
+// loadLinks returns the links of the given contacts, ignoring deleted
+// applications.
+func loadLinks(db *gorm.DB, contactIDs []uint) (map[uint]links, error) {
+	out := make(map[uint]links, len(contactIDs))
+	if len(contactIDs) == 0 {
+		return out, nil
+	}
+
+	type pair struct {
+		ContactID uint
+		ID        uint
+	}
+	var apps, companies []pair
+	if err := db.Table(applicationLinks+" l").
+		Select("l.contact_id, l.job_application_id AS id").
+		Joins("JOIN job_applications ja ON ja.id = l.job_application_id AND ja.deleted_at IS NULL").
+		Where("l.contact_id IN ?", contactIDs).
+		Order("l.job_application_id").
+		Scan(&apps).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Table(companyLinks).
+		Select("contact_id, company_id AS id").
+		Where("contact_id IN ?", contactIDs).
+		Order("company_id").
+		Scan(&companies).Error; err != nil {
+		return nil, err
+	}
+
+	for _, p := range apps {
+		l := out[p.ContactID]
+		l.applications = append(l.applications, p.ID)
+		out[p.ContactID] = l
+	}
+	for _, p := range companies {
+		l := out[p.ContactID]
+		l.companies = append(l.companies, p.ID)
+		out[p.ContactID] = l
+	}
+	return out, nil
+}
+
+// LinkApplication records the single contact an application names (its
+// ContactName and ContactEmail) as a contact of the application and of its
+// company. The contact is found by email, else by name among the company's
+// contacts, and created if missing. A blank name and email link nothing.
+func LinkApplication(tx *gorm.DB, userID, applicationID uint, companyID *uint, name, email *string) error {
+	m := Contact{UserID: userID}
+	if name != nil {
+		m.Name = strings.TrimSpace(*name)
+	}
+	if email != nil && strings.TrimSpace(*email) != "" {
+		normalized, err := validate.NormalizeAndValidateEmail(*email)
+		if err != nil {
+			return validate.Errors{{Field: "contactEmail", Message: "must be a valid email address"}}
+		}
+		m.Email = &normalized
+	}
+	if m.Name == "" && m.Email == nil {
+		return nil
+	}
+	if m.Name == "" {
+		m.Name, _, _ = strings.Cut(*m.Email, "@")
+	}
+
+	var found Contact
+	var err error
+	switch {
+	case m.Email != nil:
+		err = tx.Where("user_id = ? AND email = ?", userID, *m.Email).Take(&found).Error
+	case companyID != nil:
+		err = tx.Where("user_id = ? AND lower(name) = lower(?)", userID, m.Name).
+			Where("id IN (SELECT contact_id FROM "+companyLinks+" WHERE company_id = ?)", *companyID).
+			Order("id").Take(&found).Error
+	default:
+		err = gorm.ErrRecordNotFound
+	}
+	switch {
+	case err == nil:
+		m = found
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	if err := link(tx, applicationLinks, "job_application_id", applicationID, m.ID); err != nil {
+		return err
+	}
+	if companyID != nil {
+		return link(tx, companyLinks, "company_id", *companyID, m.ID)
+	}
+	return nil
+}
+
+func link(tx *gorm.DB, table, column string, id, contactID uint) error {
+	return tx.Table(table).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(map[string]any{column: id, "contact_id": contactID}).Error
+}
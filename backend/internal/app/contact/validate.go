@@ -0,0 +1,38 @@
+package contact
+
+import (
+	"net/url"
+	"strings"
+
+	"appliedTo/internal/platform/validate"
+)
+
+// normalizeAndValidate checks a contact after a create, update or patch has
+// been mapped onto it and normalizes its email address. Blank optional
+// fields are cleared.
+func normalizeAndValidate(m *Contact) error {
+	var errs validate.Errors
+
+	errs.Required(validate.Field{Name: "name", Value: m.Name})
+	for _, f := range []**string{&m.Role, &m.Email, &m.Phone, &m.LinkedInURL, &m.Notes} {
+		if *f != nil && strings.TrimSpace(**f) == "" {
+			*f = nil
+		}
+	}
+
+	if m.Email != nil {
+		email, err := validate.NormalizeAndValidateEmail(*m.Email)
+		if err != nil {
+			errs.Add("email", "must be a valid email address")
+		} else {
+			m.Email = &email
+		}
+	}
+	if m.LinkedInURL != nil {
+		if u, err := url.Parse(*m.LinkedInURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs.Add("linkedinUrl", "must be an http or https URL")
+		}
+	}
+
+	return errs.Err()
+}
@@ -0,0 +1,16 @@
+package documentapi
+
+import (
+	"net/http"
+
+	"appliedTo/internal/app/document"
+	"appliedTo/internal/platform/http/problem"
+	"appliedTo/internal/platform/storage"
+)
+
+func init() {
+	problem.Register(document.ErrDocumentTooLarge, http.StatusRequestEntityTooLarge, "document_too_large", "Document too large")
+	problem.Register(document.ErrEmptyDocument, http.StatusBadRequest, "empty_document", "Document is empty")
+	problem.Register(document.ErrUnsupportedType, http.StatusUnsupportedMediaType, "unsupported_document_type", "Unsupported document type")
+	problem.Register(storage.ErrNotFound, http.StatusNotFound, "document_content_missing", "Document contents not found")
+}
@@ -0,0 +1,313 @@
+package documentapi
+
+import (
+	"appliedTo/internal/app/document"
+	"appliedTo/internal/platform/http/middleware"
+	"appliedTo/internal/platform/http/problem"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is allowed on top of the document size for the
+// multipart framing and form fields of an upload.
+const multipartOverhead = 64 << 10
+
+type Handlers struct {
+	Svc *document.Service
+}
+
+func NewHandlers(s *document.Service) *Handlers { return &Handlers{Svc: s} }
+
+// @Summary List documents
+// @Description Lists the caller's documents, newest first, with the job applications each is linked to.
+// @Tags document
+// @Security BearerAuth
+// @Produce  json
+// @Param   jobApplicationId  query  int     false  "Only documents linked to this application"
+// @Param   kind              query  string  false  "Resume, CoverLetter or Other"
+// @Success 200 {object} map[string][]document.DocumentPublicDto "Documents"
+// @Failure 400 {object} problem.Problem "Invalid query"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /documents [get]
+func (h *Handlers) ListDocuments(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var f document.DocumentFilter
+	if err := c.ShouldBindQuery(&f); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.List(c.Request.Context(), userID, f)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"documents": out})
+}
+
+// @Summary Get a document
+// @Tags document
+// @Security BearerAuth
+// @Produce  json
+// @Param   documentId  path  int  true  "Document ID"
+// @Success 200 {object} map[string]document.DocumentPublicDto "Document"
+// @Failure 400 {object} problem.Problem "Invalid document id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Document not found"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /documents/{documentId} [get]
+func (h *Handlers) GetDocument(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyDocumentID)
+
+	out, err := h.Svc.GetByID(c.Request.Context(), userID, id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"document": out})
+}
+
+// @Summary Download a document
+// @Tags document
+// @Security BearerAuth
+// @Produce  octet-stream
+// @Param   documentId  path  int  true  "Document ID"
+// @Success 200 {file} file "Document contents"
+// @Failure 400 {object} problem.Problem "Invalid document id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Document not found"
+// @Failure 500 {object} problem.Problem "Could not read document"
+// @Router /documents/{documentId}/content [get]
+func (h *Handlers) DownloadDocument(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyDocumentID)
+
+	m, rc, err := h.Svc.Open(c.Request.Context(), userID, id, nil)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	serve(c, m, rc)
+}
+
+// @Summary Delete a document
+// @Description Deletes the document and its contents and removes it from every job application it is linked to.
+// @Tags document
+// @Security BearerAuth
+// @Produce  json
+// @Param   documentId  path  int  true  "Document ID"
+// @Success 200 {object} map[string]string "Document deleted successfully"
+// @Failure 400 {object} problem.Problem "Invalid document id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Document not found"
+// @Failure 500 {object} problem.Problem "Could not delete document"
+// @Router /documents/{documentId} [delete]
+func (h *Handlers) DeleteDocument(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyDocumentID)
+
+	if err := h.Svc.Delete(c.Request.Context(), userID, id); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted successfully"})
+}
+
+// @Summary List the documents of a job application
+// @Tags document
+// @Security BearerAuth
+// @Produce  json
+// @Param   id  path  int  true  "JobApplication ID"
+// @Success 200 {object} map[string][]document.DocumentPublicDto "Documents"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application not found"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /job_application/{id}/documents [get]
+func (h *Handlers) ListApplicationDocuments(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+
+	out, err := h.Svc.List(c.Request.Context(), userID, document.DocumentFilter{JobApplicationID: &appID})
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"documents": out})
+}
+
+// @Summary Upload a document
+// @Description Uploads a resume, cover letter or other file as multipart field "file" and links it to the job application. PDF, Word, OpenDocument, RTF and plain text files are accepted; the type is detected from the contents. Uploading a file the caller already has links the existing document, which keeps its kind and filename.
+// @Tags document
+// @Security BearerAuth
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param   id    path      int     true   "JobApplication ID"
+// @Param   file  formData  file    true   "Document"
+// @Param   kind  formData  string  false  "Resume, CoverLetter or Other (default)"
+// @Success 201 {object} map[string]document.DocumentPublicDto "Linked document"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application not found"
+// @Failure 413 {object} problem.Problem "Document too large"
+// @Failure 415 {object} problem.Problem "Unsupported document type"
+// @Failure 500 {object} problem.Problem "Could not store document"
+// @Router /job_application/{id}/documents [post]
+func (h *Handlers) UploadDocument(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Svc.MaxBytes()+multipartOverhead)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Error(c, document.ErrDocumentTooLarge)
+		} else {
+			problem.InvalidPayload(c)
+		}
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	defer f.Close()
+
+	out, err := h.Svc.Upload(c.Request.Context(), userID, appID, document.Upload{
+		Filename: fh.Filename,
+		Kind:     c.PostForm("kind"),
+		Size:     fh.Size,
+		Body:     f,
+	})
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"document": out})
+}
+
+// @Summary Link a document to a job application
+// @Description Links one of the caller's existing documents, such as a resume version used before, to the job application.
+// @Tags document
+// @Security BearerAuth
+// @Produce  json
+// @Param   id          path  int  true  "JobApplication ID"
+// @Param   documentId  path  int  true  "Document ID"
+// @Success 200 {object} map[string]document.DocumentPublicDto "Linked document"
+// @Failure 400 {object} problem.Problem "Invalid id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application or document not found"
+// @Failure 500 {object} problem.Problem "Could not link document"
+// @Router /job_application/{id}/documents/{documentId} [put]
+func (h *Handlers) LinkDocument(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+	id := c.GetUint(middleware.CtxKeyDocumentID)
+
+	out, err := h.Svc.Link(c.Request.Context(), userID, appID, id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"document": out})
+}
+
+// @Summary Download a document of a job application
+// @Tags document
+// @Security BearerAuth
+// @Produce  octet-stream
+// @Param   id          path  int  true  "JobApplication ID"
+// @Param   documentId  path  int  true  "Document ID"
+// @Success 200 {file} file "Document contents"
+// @Failure 400 {object} problem.Problem "Invalid id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Document not linked to the application"
+// @Failure 500 {object} problem.Problem "Could not read document"
+// @Router /job_application/{id}/documents/{documentId}/content [get]
+func (h *Handlers) DownloadApplicationDocument(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+	id := c.GetUint(middleware.CtxKeyDocumentID)
+
+	m, rc, err := h.Svc.Open(c.Request.Context(), userID, id, &appID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	serve(c, m, rc)
+}
+
+// @Summary Unlink a document from a job application
+// @Description Removes the document from the job application. The document stays available for other applications.
+// @Tags document
+// @Security BearerAuth
+// @Produce  json
+// @Param   id          path  int  true  "JobApplication ID"
+// @Param   documentId  path  int  true  "Document ID"
+// @Success 200 {object} map[string]string "Document unlinked successfully"
+// @Failure 400 {object} problem.Problem "Invalid id"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Document not linked to the application"
+// @Failure 500 {object} problem.Problem "Could not unlink document"
+// @Router /job_application/{id}/documents/{documentId} [delete]
+func (h *Handlers) UnlinkDocument(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+	id := c.GetUint(middleware.CtxKeyDocumentID)
+
+	if err := h.Svc.Unlink(c.Request.Context(), userID, appID, id); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Document unlinked successfully"})
+}
+
+// serve streams a document as an attachment. The sniffed content type is
+// sent with nosniff so browsers do not reinterpret the file.
+func serve(c *gin.Context, m document.Document, rc io.ReadCloser) {
+	defer rc.Close()
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": m.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	c.DataFromReader(http.StatusOK, m.Size, m.ContentType, rc, map[string]string{
+		"Content-Disposition":    disposition,
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   `"` + m.SHA256 + `"`,
+	})
+}
@@ -0,0 +1,38 @@
+package documentapi
+
+import (
+	"appliedTo/internal/platform/http/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupDocumentRoutes(h *Handlers, requireAuth, requireDocumentID gin.HandlerFunc) routes.RouteConfig {
+	return routes.RouteConfig{
+		Prefix: "/documents",
+		Use:    []gin.HandlerFunc{requireAuth},
+		Register: func(g *gin.RouterGroup) {
+			g.GET("", h.ListDocuments)
+			withID := g.Group("/:documentId", requireDocumentID)
+			withID.GET("", h.GetDocument)
+			withID.GET("/content", h.DownloadDocument)
+			withID.DELETE("", h.DeleteDocument)
+		},
+	}
+}
+
+// SetupJobApplicationDocumentRoutes registers the documents of a single job
+// application under /job_application/:id/documents.
+func SetupJobApplicationDocumentRoutes(h *Handlers, requireAuth, requireApplicationID, requireDocumentID gin.HandlerFunc) routes.RouteConfig {
+	return routes.RouteConfig{
+		Prefix: "/job_application/:id/documents",
+		Use:    []gin.HandlerFunc{requireAuth, requireApplicationID},
+		Register: func(g *gin.RouterGroup) {
+			g.GET("", h.ListApplicationDocuments)
+			g.POST("", h.UploadDocument)
+			withID := g.Group("/:documentId", requireDocumentID)
+			withID.PUT("", h.LinkDocument)
+			withID.GET("/content", h.DownloadApplicationDocument)
+			withID.DELETE("", h.UnlinkDocument)
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package document
+
+import (
+	"io"
+	"time"
+)
+
+type DocumentPublicDto struct {
+	ID                uint      `json:"id"`
+	Filename          string    `json:"filename"`
+	Kind              string    `json:"kind"`
+	ContentType       string    `json:"contentType"`
+	Size              int64     `json:"size"`
+	SHA256            string    `json:"sha256"`
+	UploadedAt        time.Time `json:"uploadedAt"`
+	JobApplicationIDs []uint    `json:"jobApplicationIds"`
+}
+
+// DocumentFilter narrows a listing of documents.
+type DocumentFilter struct {
+	JobApplicationID *uint  `form:"jobApplicationId"`
+	Kind             string `form:"kind"`
+}
+
+// Upload is a file received from a client. Body is read twice, once to
+// sniff and hash it and once to store it.
+type Upload struct {
+	Filename string
+	Kind     string
+	Size     int64
+	Body     io.ReadSeeker
+}
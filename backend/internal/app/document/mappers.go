@@ -0,0 +1,19 @@
+package document
+
+// --- OUTPUT MAPPER ---
+
+func MapModelToPublicDto(m Document, applications []uint) DocumentPublicDto {
+	if applications == nil {
+		applications = []uint{}
+	}
+	return DocumentPublicDto{
+		ID:                m.ID,
+		Filename:          m.Filename,
+		Kind:              string(m.Kind),
+		ContentType:       m.ContentType,
+		Size:              m.Size,
+		SHA256:            m.SHA256,
+		UploadedAt:        m.UploadedAt.UTC(),
+		JobApplicationIDs: applications,
+	}
+}
@@ -0,0 +1,29 @@
+package document
+
+import "time"
+
+// Document is an uploaded file such as a resume or cover letter. Its
+// contents live in a storage.Store under StorageKey; the same document can
+// be linked to many job applications through job_application_documents.
+type Document struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	UserID      uint         `json:"-" gorm:"not null;index"`
+	Filename    string       `json:"filename" gorm:"not null"`
+	Kind        DocumentKind `json:"kind" gorm:"type:VARCHAR(24);not null"`
+	ContentType string       `json:"contentType" gorm:"not null"`
+	Size        int64        `json:"size" gorm:"not null"`
+	SHA256      string       `json:"sha256" gorm:"column:sha256;type:CHAR(64);not null"`
+	StorageKey  string       `json:"-" gorm:"not null"`
+	UploadedAt  time.Time    `json:"uploadedAt" gorm:"autoCreateTime"`
+}
+
+type DocumentKind string
+const (
+	KindResume      DocumentKind = "Resume"
+	KindCoverLetter DocumentKind = "CoverLetter"
+	KindOther       DocumentKind = "Other"
+)
+
+var DocumentKinds = []DocumentKind{KindResume, KindCoverLetter, KindOther}
+
+const applicationLinks = "job_application_documents"
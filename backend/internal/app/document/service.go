@@ -0,0 +1,374 @@
+package document
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"appliedTo/internal/platform/storage"
+	"appliedTo/internal/platform/validate"
+)
+
+const (
+	defaultMaxBytes = 10 << 20
+	maxFilenameLen  = 255
+)
+
+var (
+	ErrDocumentTooLarge = errors.New("document too large")
+	ErrEmptyDocument    = errors.New("document is empty")
+	ErrUnsupportedType  = errors.New("unsupported document type")
+)
+
+type Service struct {
+	db       *gorm.DB
+	store    storage.Store
+	maxBytes int64
+}
+
+// ---- Options pattern ----
+
+type Option func(*Service)
+
+// WithMaxBytes sets the largest document that can be uploaded.
+func WithMaxBytes(n int64) Option {
+	return func(s *Service) {
+		if n > 0 {
+			s.maxBytes = n
+		}
+	}
+}
+
+func NewService(db *gorm.DB, store storage.Store, opts ...Option) *Service {
+	s := &Service{db: db, store: store, maxBytes: defaultMaxBytes}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// MaxBytes is the largest document that can be uploaded.
+func (s *Service) MaxBytes() int64 { return s.maxBytes }
+
+// owned scopes a query to the documents of the given user.
+func (s *Service) owned(ctx context.Context, userID uint) *gorm.DB {
+	return s.db.WithContext(ctx).Where("user_id = ?", userID)
+}
+
+// CREATE
+// Upload stores a file and links it to the application. The content type is
+// sniffed from the file. Uploading a file the user already has (same SHA-256)
+// links the existing document instead of storing a copy; that document keeps
+// its kind and filename.
+func (s *Service) Upload(ctx context.Context, userID, appID uint, in Upload) (DocumentPublicDto, error) {
+	m := Document{UserID: userID, Kind: DocumentKind(in.Kind), Filename: cleanFilename(in.Filename)}
+	if m.Kind == "" {
+		m.Kind = KindOther
+	}
+	var errs validate.Errors
+	validate.OneOf(&errs, "kind", m.Kind, DocumentKinds...)
+	if m.Filename == "" {
+		errs.Add("filename", "is required")
+	}
+	if err := errs.Err(); err != nil {
+		return DocumentPublicDto{}, err
+	}
+	if in.Size > s.maxBytes {
+		return DocumentPublicDto{}, ErrDocumentTooLarge
+	}
+
+	if err := s.inspect(&m, in.Body); err != nil {
+		return DocumentPublicDto{}, err
+	}
+	if _, err := ownedApplication(s.db.WithContext(ctx), userID, appID); err != nil {
+		return DocumentPublicDto{}, err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockContents(tx, m.StorageKey); err != nil {
+			return err
+		}
+		var existing Document
+		err := tx.Where("user_id = ? AND sha256 = ?", userID, m.SHA256).Take(&existing).Error
+		switch {
+		case err == nil:
+			m = existing
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if m, err = s.create(ctx, tx, m, in.Body); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+		return link(tx, appID, m.ID)
+	})
+	if err != nil {
+		return DocumentPublicDto{}, err
+	}
+	return s.load(ctx, m)
+}
+
+// inspect sniffs, measures and hashes body, then rewinds it.
+func (s *Service) inspect(m *Document, body io.ReadSeeker) error {
+	contentType, err := sniff(body)
+	if err != nil {
+		return err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, io.LimitReader(body, s.maxBytes+1))
+	if err != nil {
+		return err
+	}
+	switch {
+	case n == 0:
+		return ErrEmptyDocument
+	case n > s.maxBytes:
+		return ErrDocumentTooLarge
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	m.ContentType = contentType
+	m.Size = n
+	m.SHA256 = hex.EncodeToString(h.Sum(nil))
+	m.StorageKey = fmt.Sprintf("users/%d/%s", m.UserID, m.SHA256)
+	return nil
+}
+
+// create writes a new document's contents and inserts its row. The caller
+// holds the lock of the contents, so no other upload or delete of the same
+// file runs meanwhile.
+func (s *Service) create(ctx context.Context, tx *gorm.DB, m Document, body io.Reader) (Document, error) {
+	if err := s.store.Put(ctx, m.StorageKey, body, m.Size, m.ContentType); err != nil {
+		return Document{}, err
+	}
+	if err := tx.Create(&m).Error; err != nil {
+		s.discard(m.StorageKey)
+		return Document{}, err
+	}
+	return m, nil
+}
+
+// READ
+func (s *Service) List(ctx context.Context, userID uint, f DocumentFilter) ([]DocumentPublicDto, error) {
+	q := s.owned(ctx, userID)
+	if f.JobApplicationID != nil {
+		if _, err := ownedApplication(s.db.WithContext(ctx), userID, *f.JobApplicationID); err != nil {
+			return nil, err
+		}
+		q = q.Where("id IN (SELECT document_id FROM "+applicationLinks+" WHERE job_application_id = ?)", *f.JobApplicationID)
+	}
+	if f.Kind != "" {
+		q = q.Where("kind = ?", f.Kind)
+	}
+
+	var docs []Document
+	if err := q.Order("uploaded_at DESC, id DESC").Find(&docs).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	all, err := loadLinks(s.db.WithContext(ctx), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]DocumentPublicDto, 0, len(docs))
+	for _, d := range docs {
+		out = append(out, MapModelToPublicDto(d, all[d.ID]))
+	}
+	return out, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, userID, id uint) (DocumentPublicDto, error) {
+	var m Document
+	if err := s.owned(ctx, userID).First(&m, id).Error; err != nil {
+		return DocumentPublicDto{}, err
+	}
+	return s.load(ctx, m)
+}
+
+// Open returns a document and its contents, which the caller must close.
+// With an application ID the document must be linked to that application.
+func (s *Service) Open(ctx context.Context, userID, id uint, appID *uint) (Document, io.ReadCloser, error) {
+	q := s.owned(ctx, userID)
+	if appID != nil {
+		if _, err := ownedApplication(s.db.WithContext(ctx), userID, *appID); err != nil {
+			return Document{}, nil, err
+		}
+		q = q.Where("id IN (SELECT document_id FROM "+applicationLinks+" WHERE job_application_id = ?)", *appID)
+	}
+	var m Document
+	if err := q.First(&m, id).Error; err != nil {
+		return Document{}, nil, err
+	}
+	rc, err := s.store.Open(ctx, m.StorageKey)
+	if err != nil {
+		return Document{}, nil, err
+	}
+	return m, rc, nil
+}
+
+// UPDATE
+// Link attaches an existing document to an application.
+func (s *Service) Link(ctx context.Context, userID, appID, id uint) (DocumentPublicDto, error) {
+	if _, err := ownedApplication(s.db.WithContext(ctx), userID, appID); err != nil {
+		return DocumentPublicDto{}, err
+	}
+	var m Document
+	if err := s.owned(ctx, userID).First(&m, id).Error; err != nil {
+		return DocumentPublicDto{}, err
+	}
+	if err := link(s.db.WithContext(ctx), appID, m.ID); err != nil {
+		return DocumentPublicDto{}, err
+	}
+	return s.load(ctx, m)
+}
+
+// Unlink detaches a document from an application. The document itself is
+// kept.
+func (s *Service) Unlink(ctx context.Context, userID, appID, id uint) error {
+	if _, err := ownedApplication(s.db.WithContext(ctx), userID, appID); err != nil {
+		return err
+	}
+	res := s.db.WithContext(ctx).
+		Where("job_application_id = ? AND document_id IN (?)", appID,
+			s.owned(ctx, userID).Model(&Document{}).Select("id").Where("id = ?", id)).
+		Delete(&applicationLink{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DELETE
+// Delete removes a document from every application and deletes its
+// contents. Both happen under the lock of the contents, so that an upload of
+// the same file either still finds the document or stores the contents
+// again.
+func (s *Service) Delete(ctx context.Context, userID, id uint) error {
+	var m Document
+	if err := s.owned(ctx, userID).Select("id", "storage_key").First(&m, id).Error; err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockContents(tx, m.StorageKey); err != nil {
+			return err
+		}
+		res := tx.Where("user_id = ?", userID).Delete(&Document{}, m.ID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		// Rolls the row back if the contents cannot be deleted.
+		return s.store.Delete(ctx, m.StorageKey)
+	})
+}
+
+// -------- helpers --------
+
+// applicationLink is a row of job_application_documents.
+type applicationLink struct {
+	JobApplicationID uint `gorm:"primaryKey"`
+	DocumentID       uint `gorm:"primaryKey"`
+}
+
+func (applicationLink) TableName() string { return applicationLinks }
+
+// ownedApplication checks that the application exists and belongs to the
+// user.
+func ownedApplication(db *gorm.DB, userID, id uint) (uint, error) {
+	var found uint
+	err := db.Table("job_applications").Select("id").
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, userID).
+		Take(&found).Error
+	return found, err
+}
+
+func link(db *gorm.DB, appID, documentID uint) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&applicationLink{JobApplicationID: appID, DocumentID: documentID}).Error
+}
+
+func (s *Service) load(ctx context.Context, m Document) (DocumentPublicDto, error) {
+	all, err := loadLinks(s.db.WithContext(ctx), []uint{m.ID})
+	if err != nil {
+		return DocumentPublicDto{}, err
+	}
+	return MapModelToPublicDto(m, all[m.ID]), nil
+}
+
+// loadLinks returns the applications each document is linked to, ignoring
+// deleted applications.
+func loadLinks(db *gorm.DB, documentIDs []uint) (map[uint][]uint, error) {
+	out := make(map[uint][]uint, len(documentIDs))
+	if len(documentIDs) == 0 {
+		return out, nil
+	}
+	var rows []applicationLink
+	if err := db.Table(applicationLinks+" l").
+		Select("l.document_id, l.job_application_id").
+		Joins("JOIN job_applications ja ON ja.id = l.job_application_id AND ja.deleted_at IS NULL").
+		Where("l.document_id IN ?", documentIDs).
+		Order("l.job_application_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.DocumentID] = append(out[r.DocumentID], r.JobApplicationID)
+	}
+	return out, nil
+}
+
+// lockContents takes a transaction-scoped advisory lock on the contents at
+// key, which is unique per user and SHA-256.
+func lockContents(tx *gorm.DB, key string) error {
+	return tx.Exec(`SELECT pg_advisory_xact_lock(hashtextextended(?, 0))`, key).Error
+}
+
+// discard deletes stored contents that no document refers to any more. A
+// failure only leaves an orphaned object behind.
+func (s *Service) discard(key string) {
+	if err := s.store.Delete(context.Background(), key); err != nil {
+		log.Printf("document: delete %s: %v", key, err)
+	}
+}
+
+// cleanFilename keeps the base name of a client-supplied file name.
+func cleanFilename(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "." || name == "/" {
+		return ""
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	for len(name) > maxFilenameLen {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
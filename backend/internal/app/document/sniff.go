@@ -0,0 +1,33 @@
+package document
+
+import (
+	"io"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// allowedTypes are the formats accepted for documents. The type is sniffed
+// from the contents; the client's Content-Type and file extension are
+// ignored.
+var allowedTypes = []string{
+	"application/pdf",
+	"application/msword",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.oasis.opendocument.text",
+	"text/rtf",
+	"text/plain",
+}
+
+// sniff returns the content type of r if it is an allowed one.
+func sniff(r io.Reader) (string, error) {
+	mt, err := mimetype.DetectReader(r)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range allowedTypes {
+		if mt.Is(t) {
+			return mt.String(), nil
+		}
+	}
+	return "", ErrUnsupportedType
+}
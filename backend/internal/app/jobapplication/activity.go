@@ -0,0 +1,139 @@
+package jobapplication
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"appliedTo/internal/platform/validate"
+)
+
+// activityCursorSort tags activity cursors so that list cursors are rejected.
+const activityCursorSort = "activity"
+
+// LIST
+func (s *Service) Activities(ctx context.Context, userID, appID uint, in ActivityListQuery) (ActivityListDto, error) {
+	if _, err := ownedApplication(s.db.WithContext(ctx), userID, appID); err != nil {
+		return ActivityListDto{}, err
+	}
+
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	q := s.db.WithContext(ctx).Where("job_application_id = ?", appID)
+	if in.Cursor != "" {
+		at, id, err := decodeActivityCursor(in.Cursor)
+		if err != nil {
+			return ActivityListDto{}, err
+		}
+		q = q.Where("(occurred_at, id) < (?, ?)", at, id)
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	var rows []Activity
+	if err := q.Order("occurred_at DESC, id DESC").Limit(limit + 1).Find(&rows).Error; err != nil {
+		return ActivityListDto{}, err
+	}
+
+	out := ActivityListDto{Items: make([]ActivityPublicDto, 0, min(len(rows), limit))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		next, err := encodeActivityCursor(rows[len(rows)-1])
+		if err != nil {
+			return ActivityListDto{}, err
+		}
+		out.NextCursor = &next
+	}
+	for _, a := range rows {
+		out.Items = append(out.Items, MapActivityToPublicDto(a))
+	}
+	return out, nil
+}
+
+// CREATE
+// LogActivity appends an activity to the application's timeline. A contact
+// activity that is newer than the application's LastContactAt moves it
+// forward through patchInTx, in the same transaction.
+func (s *Service) LogActivity(ctx context.Context, userID, appID uint, in ActivityCreateDto) (ActivityPublicDto, error) {
+	a := Activity{
+		JobApplicationID: appID,
+		Type:             ActivityType(in.Type),
+		Body:             in.Body,
+		CreatedByUserID:  userID,
+	}
+	if in.OccurredAt != nil {
+		a.OccurredAt = *in.OccurredAt
+	} else {
+		a.OccurredAt = time.Now()
+	}
+	if a.Body != nil && strings.TrimSpace(*a.Body) == "" {
+		a.Body = nil
+	}
+
+	var errs validate.Errors
+	validate.OneOf(&errs, "type", a.Type, ActivityTypes...)
+	if a.Type == ActivityNote && a.Body == nil {
+		errs.Add("body", "is required for notes")
+	}
+	if err := errs.Err(); err != nil {
+		return ActivityPublicDto{}, err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var app JobApplication
+		if err := tx.Where("user_id = ?", userID).Select("id", "last_contact_at").First(&app, appID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&a).Error; err != nil {
+			return err
+		}
+		if a.Type.IsContact() && (app.LastContactAt == nil || a.OccurredAt.After(*app.LastContactAt)) {
+			at := a.OccurredAt
+			if _, err := patchInTx(tx, userID, appID, JobApplicationPatchDto{LastContactAt: &at}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ActivityPublicDto{}, err
+	}
+	return MapActivityToPublicDto(a), nil
+}
+
+func encodeActivityCursor(a Activity) (string, error) {
+	v, err := json.Marshal(a.OccurredAt.UTC())
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(cursor{Sort: activityCursorSort, Value: v, ID: a.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeActivityCursor(raw string) (time.Time, uint, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	var cur cursor
+	if err := json.Unmarshal(b, &cur); err != nil || cur.ID == 0 || cur.Sort != activityCursorSort {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	var at time.Time
+	if err := json.Unmarshal(cur.Value, &at); err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return at, cur.ID, nil
+}
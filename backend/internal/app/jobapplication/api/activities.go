@@ -0,0 +1,80 @@
+package jobapplicationapi
+
+import (
+	"appliedTo/internal/app/jobapplication"
+	"appliedTo/internal/platform/http/middleware"
+	"appliedTo/internal/platform/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List the activities of a job application
+// @Description Returns the application's activity log, newest first. Pass nextCursor back as cursor to fetch the following page.
+// @Tags activity
+// @Security BearerAuth
+// @Produce  json
+// @Param   id      path   int     true   "JobApplication ID"
+// @Param   limit   query  int     false  "Page size (default 20, max 100)"
+// @Param   cursor  query  string  false  "Cursor from a previous page"
+// @Success 200 {object} jobapplication.ActivityListDto "Activities"
+// @Failure 400 {object} problem.Problem "Invalid query or cursor"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application not found"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /job_application/{id}/activities [get]
+func (h *Handlers) ListActivities(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+
+	var q jobapplication.ActivityListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.Activities(c.Request.Context(), userID, appID, q)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// @Summary Log an activity
+// @Description Appends a note, email, call, interview or offer to the application's activity log. Activities cannot be edited or deleted. Any activity other than a note moves lastContactAt forward if it is newer.
+// @Tags activity
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   id        path  int                               true  "JobApplication ID"
+// @Param   activity  body  jobapplication.ActivityCreateDto  true  "Activity data"
+// @Success 201 {object} map[string]jobapplication.ActivityPublicDto "Logged activity"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application not found"
+// @Failure 500 {object} problem.Problem "Could not log activity"
+// @Router /job_application/{id}/activities [post]
+func (h *Handlers) CreateActivity(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+
+	var in jobapplication.ActivityCreateDto
+	if err := c.ShouldBindJSON(&in); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.LogActivity(c.Request.Context(), userID, appID, in)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"activity": out})
+}
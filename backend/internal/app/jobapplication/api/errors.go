@@ -0,0 +1,21 @@
+package jobapplicationapi
+
+import (
+	"net/http"
+
+	"appliedTo/internal/app/jobapplication"
+	"appliedTo/internal/platform/http/problem"
+)
+
+func init() {
+	problem.Register(jobapplication.ErrInvalidSort, http.StatusBadRequest, "invalid_sort", "Invalid sort field")
+	problem.Register(jobapplication.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor", "Invalid cursor")
+	problem.Register(jobapplication.ErrUnknownStatus, http.StatusBadRequest, "unknown_status", "Unknown application status")
+	problem.Register(jobapplication.ErrInvalidTransition, http.StatusConflict, "invalid_status_transition", "Status transition not allowed")
+	problem.Register(jobapplication.ErrEmptyImport, http.StatusBadRequest, "empty_import", "Nothing to import")
+	problem.Register(jobapplication.ErrImportTooLarge, http.StatusRequestEntityTooLarge, "import_too_large", "Import too large")
+	problem.Register(jobapplication.ErrInvalidCSV, http.StatusBadRequest, "invalid_csv", "Invalid CSV file")
+	problem.Register(jobapplication.ErrInvalidExportFormat, http.StatusBadRequest, "invalid_export_format", "Invalid export format")
+	problem.Register(jobapplication.ErrInvalidMapping, http.StatusBadRequest, "invalid_mapping", "Invalid column mapping")
+	problem.Register(jobapplication.ErrPossibleDuplicate, http.StatusConflict, "possible_duplicate", "Possible duplicate application")
+}
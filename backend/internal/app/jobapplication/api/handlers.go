@@ -3,11 +3,14 @@ package jobapplicationapi
 import (
 	"appliedTo/internal/app/jobapplication"
 	"appliedTo/internal/platform/http/middleware"
+	"appliedTo/internal/platform/http/problem"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type Handlers struct {
@@ -18,83 +21,325 @@ func NewHandlers(s *jobapplication.Service) *Handlers { return &Handlers{Svc: s}
 
 // @Summary Create a new job application
 // @Description Creates a new job application with the provided title.
+// @Description An application that looks like an existing one (same posting URL without tracking parameters, or the same company with a similar title and location) is rejected with 409; the problem lists the matches under "duplicates". Pass force=true to create it anyway.
 // @Tags jobApplication
+// @Security BearerAuth
 // @Accept  json
 // @Produce  json
-// @Param   jobApplication  body  jobapplication.JobApplicationCreateDto  true  "JobApplication data"
+// @Param   jobApplication  body   jobapplication.JobApplicationCreateDto  true   "JobApplication data"
+// @Param   force           query  bool                                    false  "Create even if the application looks like a duplicate"
 // @Success 200 {object} map[string]interface{} "Job application created successfully"
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 500 {object} map[string]string "Could not create job application"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 409 {object} problem.Problem "Possible duplicate application"
+// @Failure 500 {object} problem.Problem "Could not create job application"
 // @Router /job_application [post]
 func (h *Handlers) CreateJobApplication(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var q jobapplication.JobApplicationCreateQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
 	var in jobapplication.JobApplicationCreateDto
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		problem.InvalidPayload(c)
 		return
 	}
 
-	out, err := h.Svc.Create(c.Request.Context(), in)
+	out, err := h.Svc.Create(c.Request.Context(), userID, in, q.Force)
 	if err != nil {
-		// validation.Required(...) returns a descriptive error string
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Job application created successfully", "job_application": out})
 }
 
+// @Summary List job applications
+// @Description Lists the caller's job applications, optionally filtered, searched and sorted. With q, results are ranked by relevance and carry a snippet: HTML with the text escaped and matches in <mark></mark>. Pages are linked by an opaque cursor; pass the returned nextCursor to fetch the following page.
+// @Tags jobApplication
+// @Security BearerAuth
+// @Produce  json
+// @Param   q                 query  string    false  "Full-text search over company, title, description, contact name and location"
+// @Param   status            query  []string  false  "Application status (repeatable)"  collectionFormat(multi)
+// @Param   source            query  []string  false  "Application source (repeatable)"  collectionFormat(multi)
+// @Param   employmentType    query  []string  false  "Employment type (repeatable)"  collectionFormat(multi)
+// @Param   workLocation      query  []string  false  "Work location (repeatable)"  collectionFormat(multi)
+// @Param   tag               query  []string  false  "Tag that must be present (repeatable)"  collectionFormat(multi)
+// @Param   appliedFrom       query  string    false  "Applied at or after (RFC3339)"
+// @Param   appliedTo         query  string    false  "Applied at or before (RFC3339)"
+// @Param   nextFollowUpFrom  query  string    false  "Next follow-up at or after (RFC3339)"
+// @Param   nextFollowUpTo    query  string    false  "Next follow-up at or before (RFC3339)"
+// @Param   lastContactFrom   query  string    false  "Last contact at or after (RFC3339)"
+// @Param   lastContactTo     query  string    false  "Last contact at or before (RFC3339)"
+// @Param   salaryMin         query  number    false  "Minimum yearly salary in salaryCurrency"
+// @Param   salaryMax         query  number    false  "Maximum yearly salary in salaryCurrency"
+// @Param   salaryCurrency    query  string    false  "Currency salaries are normalized to (default: base currency)"
+// @Param   sort              query  string    false  "Sort field, prefix with - for descending (id, status, source, employmentType, workLocation, appliedAt, nextFollowUpAt, lastContactAt, salaryFrom, salaryTo, relevance)"  default(-id)
+// @Param   limit             query  int       false  "Page size (max 100)"  default(20)
+// @Param   cursor            query  string    false  "Cursor from a previous page"
+// @Success 200 {object} jobapplication.JobApplicationListDto "Page of job applications"
+// @Failure 400 {object} problem.Problem "Invalid query or unknown salary currency"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /job_application [get]
+func (h *Handlers) ListJobApplications(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var q jobapplication.JobApplicationListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.List(c.Request.Context(), userID, q)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// @Summary Import job applications
+// @Description Creates or updates job applications in bulk from a JSON array of applications or a CSV file. Rows with an externalJobId update the application with the same source and external ID; the others are created. Each row is validated and stored on its own, and the response reports the outcome of every row. With dryRun nothing is stored.
+// @Description CSV files are uploaded as multipart field "file" (or sent as a text/csv body). The optional "mapping" (form field or query parameter) is a JSON object from field paths such as "title" or "employment.salaryRange.from" to column headers; without it the headers must be field paths.
+// @Tags jobApplication
+// @Security BearerAuth
+// @Accept  json,mpfd,text/csv
+// @Produce  json
+// @Param   dryRun           query     bool    false  "Validate and report without storing anything"
+// @Param   jobApplications  body      []jobapplication.JobApplicationCreateDto  false  "Applications to import (JSON)"
+// @Param   file             formData  file    false  "CSV file to import"
+// @Param   mapping          formData  string  false  "JSON object mapping field paths to CSV column headers"
+// @Success 200 {object} jobapplication.JobApplicationImportReportDto "Per-row import report"
+// @Failure 400 {object} problem.Problem "Invalid payload, CSV or mapping"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 413 {object} problem.Problem "Too many rows"
+// @Failure 500 {object} problem.Problem "Import failed"
+// @Router /job_application/import [post]
+func (h *Handlers) ImportJobApplications(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var q struct {
+		DryRun bool `form:"dryRun"`
+	}
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	rows, err := readImportRows(c)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			problem.Error(c, jobapplication.ErrImportTooLarge)
+		case errors.Is(err, errBadImportPayload):
+			problem.InvalidPayload(c)
+		default:
+			problem.Error(c, err)
+		}
+		return
+	}
+
+	report, err := h.Svc.Import(c.Request.Context(), userID, rows, q.DryRun)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		if row.Err == nil {
+			continue
+		}
+		p := problem.From(row.Err)
+		if p.Status >= http.StatusInternalServerError {
+			log.Printf("import row %d for user %d: %v", row.Row, userID, row.Err)
+		}
+		row.Error = &jobapplication.ImportRowErrorDto{Code: p.Code, Title: p.Title, Errors: p.Errors}
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Export job applications
+// @Description Downloads the caller's applications matching the list filters, oldest first. csv flattens employment and salary into columns named like the import field paths, so the file can be imported again; json is an array of applications; ics is an iCalendar file with an event for every next follow-up and every interview that was not cancelled.
+// @Tags jobApplication
+// @Security BearerAuth
+// @Produce  json,text/csv,text/calendar
+// @Param   format            query  string    false  "Export format"  Enums(csv, json, ics)  default(json)
+// @Param   q                 query  string    false  "Full-text search over company, title, description, contact name and location"
+// @Param   status            query  []string  false  "Application status (repeatable)"  collectionFormat(multi)
+// @Param   source            query  []string  false  "Application source (repeatable)"  collectionFormat(multi)
+// @Param   employmentType    query  []string  false  "Employment type (repeatable)"  collectionFormat(multi)
+// @Param   workLocation      query  []string  false  "Work location (repeatable)"  collectionFormat(multi)
+// @Param   tag               query  []string  false  "Tag that must be present (repeatable)"  collectionFormat(multi)
+// @Param   appliedFrom       query  string    false  "Applied at or after (RFC3339)"
+// @Param   appliedTo         query  string    false  "Applied at or before (RFC3339)"
+// @Param   nextFollowUpFrom  query  string    false  "Next follow-up at or after (RFC3339)"
+// @Param   nextFollowUpTo    query  string    false  "Next follow-up at or before (RFC3339)"
+// @Param   lastContactFrom   query  string    false  "Last contact at or after (RFC3339)"
+// @Param   lastContactTo     query  string    false  "Last contact at or before (RFC3339)"
+// @Param   salaryMin         query  number    false  "Minimum yearly salary in salaryCurrency"
+// @Param   salaryMax         query  number    false  "Maximum yearly salary in salaryCurrency"
+// @Param   salaryCurrency    query  string    false  "Currency salaries are normalized to (default: base currency)"
+// @Success 200 {file} file "Exported applications"
+// @Failure 400 {object} problem.Problem "Invalid query or format"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 500 {object} problem.Problem "Export failed"
+// @Router /job_application/export [get]
+func (h *Handlers) ExportJobApplications(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var q jobapplication.JobApplicationExportQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+	if q.Format == "" {
+		q.Format = jobapplication.ExportJSON
+	}
+	w, err := jobapplication.NewExportWriter(q.Format, c.Writer)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.Header("Content-Type", q.Format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="job_applications.%s"`, q.Format))
+	if err := h.Svc.Export(c.Request.Context(), userID, q, w); err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			problem.Error(c, err)
+			return
+		}
+		// Too late for an error response. The document lacks its closing
+		// part, so JSON and iCalendar clients will notice the truncation.
+		log.Printf("export for user %d: %v", userID, err)
+		c.Abort()
+	}
+}
+
+// @Summary Report duplicate job applications
+// @Description Groups the caller's applications that look like the same job: the same posting URL without tracking parameters, or the same company with a similar title and location. Groups and their items are ordered oldest first.
+// @Tags jobApplication
+// @Security BearerAuth
+// @Produce  json
+// @Success 200 {object} jobapplication.DuplicateReportDto "Groups of likely duplicates"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /job_application/duplicates [get]
+func (h *Handlers) GetDuplicates(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.Svc.Duplicates(c.Request.Context(), userID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
 // @Summary Get a job application by ID
 // @Description Get detailed information about a job application
 // @Tags jobApplication
+// @Security BearerAuth
 // @Accept  json
 // @Produce  json
 // @Param   id  path  int  true  "JobApplication ID"
 // @Success 200 {object} jobapplication.JobApplicationPublicDto "Successfully retrieved job application"
-// @Failure 404 {object} map[string]string "Application not found"
-// @Failure 500 {object} map[string]string "Database query failed"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application not found"
+// @Failure 500 {object} problem.Problem "Database query failed"
 // @Router /job_application/{id} [get]
 func (h *Handlers) GetJobApplication(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
 	id := c.GetUint(middleware.CtxKeyJobApplicationID)
 
-	out, err := h.Svc.GetByID(c.Request.Context(), id)
+	out, err := h.Svc.GetByID(c.Request.Context(), userID, id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database query failed"})
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"job_application": out})
 }
 
+// @Summary Get the status history of a job application
+// @Description Lists every status transition of the job application, oldest first, with the acting user.
+// @Tags jobApplication
+// @Security BearerAuth
+// @Produce  json
+// @Param   id  path  int  true  "JobApplication ID"
+// @Success 200 {object} map[string][]jobapplication.StatusChangePublicDto "Status history"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application not found"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /job_application/{id}/history [get]
+func (h *Handlers) GetJobApplicationHistory(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	id := c.GetUint(middleware.CtxKeyJobApplicationID)
+
+	out, err := h.Svc.History(c.Request.Context(), userID, id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"history": out})
+}
+
 // @Summary Patch a job application
 // @Description Partially update a job application. Only fields provided in the body will be modified.
 // @Tags jobApplication
+// @Security BearerAuth
 // @Accept  json
 // @Produce  json
 // @Param   id             path  int                                       true  "JobApplication ID"
 // @Param   jobApplication body  jobapplication.JobApplicationPatchDto true  "Fields to patch"
 // @Success 200 {object} jobapplication.JobApplicationPublicDto "Updated job application"
-// @Failure 400 {object} map[string]string "Invalid payload"
-// @Failure 404 {object} map[string]string "Not found"
-// @Failure 500 {object} map[string]string "Update failed"
+// @Failure 400 {object} problem.Problem "Invalid payload"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Not found"
+// @Failure 409 {object} problem.Problem "Status transition not allowed"
+// @Failure 500 {object} problem.Problem "Update failed"
 // @Router /job_application/{id} [patch]
 func (h *Handlers) PatchJobApplication(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
 	id := c.GetUint(middleware.CtxKeyJobApplicationID)
 
 	var patch jobapplication.JobApplicationPatchDto
 	if err := c.ShouldBindJSON(&patch); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payload"})
+		problem.InvalidPayload(c)
 		return
 	}
 
-	out, err := h.Svc.Patch(c.Request.Context(), id, patch)
+	out, err := h.Svc.Patch(c.Request.Context(), userID, id, patch)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Update failed"})
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Updated", "job_application": out})
@@ -103,33 +348,34 @@ func (h *Handlers) PatchJobApplication(c *gin.Context) {
 // @Summary Update a job application (full replace)
 // @Description Replace a job application with the provided data. All fields should be supplied.
 // @Tags jobApplication
+// @Security BearerAuth
 // @Accept  json
 // @Produce  json
 // @Param   id              path  int                                       true  "JobApplication ID"
 // @Param   jobApplication  body  jobapplication.JobApplicationCreateDto true  "JobApplication data"
 // @Success 200 {object} jobapplication.JobApplicationPublicDto "Job application successfully updated."
-// @Failure 400 {object} map[string]string "Invalid input"
-// @Failure 404 {object} map[string]string "Application not found"
-// @Failure 500 {object} map[string]string "Database query failed"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application not found"
+// @Failure 409 {object} problem.Problem "Status transition not allowed"
+// @Failure 500 {object} problem.Problem "Database query failed"
 // @Router /job_application/{id} [put]
 func (h *Handlers) UpdateJobApplication(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
 	id := c.GetUint(middleware.CtxKeyJobApplicationID)
 
 	var in jobapplication.JobApplicationCreateDto
 	if err := c.ShouldBindJSON(&in); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		problem.InvalidPayload(c)
 		return
 	}
 
-	out, err := h.Svc.Update(c.Request.Context(), id, in)
+	out, err := h.Svc.Update(c.Request.Context(), userID, id, in)
 	if err != nil {
-		switch {
-		case errors.Is(err, gorm.ErrRecordNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
-		default:
-			// could be validation error or DB error
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Job application updated successfully", "job_application": out})
@@ -138,24 +384,81 @@ func (h *Handlers) UpdateJobApplication(c *gin.Context) {
 // @Summary Delete a job application.
 // @Description Remove the job application from the database by providing the job-application-ID.
 // @Tags jobApplication
+// @Security BearerAuth
 // @Accept  json
 // @Produce  json
 // @Param   id  path  int  true  "Job application ID"
 // @Success 200 {object} map[string]string "Job application deleted."
-// @Failure 404 {object} map[string]string "Not found"
-// @Failure 500 {object} map[string]string "Could not delete job application"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Not found"
+// @Failure 500 {object} problem.Problem "Could not delete job application"
 // @Router /job_application/{id} [delete]
 func (h *Handlers) DeleteJobApplication(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
 	id := c.GetUint(middleware.CtxKeyJobApplicationID)
 
-	if err := h.Svc.Delete(c.Request.Context(), id); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not delete job application"})
+	if err := h.Svc.Delete(c.Request.Context(), userID, id); err != nil {
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Job application deleted successfully"})
 }
 
+// ---- import payloads ----
+
+const maxImportBytes = 10 << 20
+
+var errBadImportPayload = errors.New("bad import payload")
+
+// readImportRows decodes the import body according to its content type.
+func readImportRows(c *gin.Context) ([]jobapplication.ImportRow, error) {
+	switch c.ContentType() {
+	case "multipart/form-data":
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.Join(errBadImportPayload, err)
+		}
+		mapping, err := parseMapping(c.PostForm("mapping"))
+		if err != nil {
+			return nil, err
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return jobapplication.ParseCSV(f, mapping)
+
+	case "text/csv":
+		mapping, err := parseMapping(c.Query("mapping"))
+		if err != nil {
+			return nil, err
+		}
+		return jobapplication.ParseCSV(c.Request.Body, mapping)
+
+	default:
+		var in []jobapplication.JobApplicationCreateDto
+		if err := c.ShouldBindJSON(&in); err != nil {
+			return nil, errors.Join(errBadImportPayload, err)
+		}
+		rows := make([]jobapplication.ImportRow, 0, len(in))
+		for i, dto := range in {
+			rows = append(rows, jobapplication.ImportRow{Row: i + 1, In: dto})
+		}
+		return rows, nil
+	}
+}
+
+func parseMapping(raw string) (jobapplication.CSVMapping, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var m jobapplication.CSVMapping
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", jobapplication.ErrInvalidMapping, err)
+	}
+	return m, nil
+}
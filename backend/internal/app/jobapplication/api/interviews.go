@@ -0,0 +1,198 @@
+package jobapplicationapi
+
+import (
+	"appliedTo/internal/app/jobapplication"
+	"appliedTo/internal/platform/http/middleware"
+	"appliedTo/internal/platform/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List the interviews of a job application
+// @Description Lists the interview rounds of the job application in schedule order.
+// @Tags interview
+// @Security BearerAuth
+// @Produce  json
+// @Param   id  path  int  true  "JobApplication ID"
+// @Success 200 {object} map[string][]jobapplication.InterviewPublicDto "Interviews"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application not found"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /job_application/{id}/interviews [get]
+func (h *Handlers) ListInterviews(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+
+	out, err := h.Svc.ListInterviews(c.Request.Context(), userID, appID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"interviews": out})
+}
+
+// @Summary Schedule an interview
+// @Description Adds an interview round to the job application. With advanceStatus, an application that has not reached the Interview status yet is moved there, recording each intermediate status change.
+// @Tags interview
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   id         path  int                                true  "JobApplication ID"
+// @Param   interview  body  jobapplication.InterviewCreateDto  true  "Interview data"
+// @Success 201 {object} map[string]interface{} "Created interview and the job application"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Application not found"
+// @Failure 409 {object} problem.Problem "Status transition not allowed"
+// @Failure 500 {object} problem.Problem "Could not create interview"
+// @Router /job_application/{id}/interviews [post]
+func (h *Handlers) CreateInterview(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+
+	var in jobapplication.InterviewCreateDto
+	if err := c.ShouldBindJSON(&in); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, app, err := h.Svc.CreateInterview(c.Request.Context(), userID, appID, in)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"interview": out, "job_application": app})
+}
+
+// @Summary Get an interview
+// @Tags interview
+// @Security BearerAuth
+// @Produce  json
+// @Param   id           path  int  true  "JobApplication ID"
+// @Param   interviewId  path  int  true  "Interview ID"
+// @Success 200 {object} map[string]jobapplication.InterviewPublicDto "Interview"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Interview not found"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /job_application/{id}/interviews/{interviewId} [get]
+func (h *Handlers) GetInterview(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+	id := c.GetUint(middleware.CtxKeyInterviewID)
+
+	out, err := h.Svc.GetInterview(c.Request.Context(), userID, appID, id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"interview": out})
+}
+
+// @Summary Update an interview (full replace)
+// @Tags interview
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   id           path  int                              true  "JobApplication ID"
+// @Param   interviewId  path  int                              true  "Interview ID"
+// @Param   interview    body  jobapplication.BaseInterviewDto  true  "Interview data"
+// @Success 200 {object} map[string]jobapplication.InterviewPublicDto "Updated interview"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Interview not found"
+// @Failure 500 {object} problem.Problem "Update failed"
+// @Router /job_application/{id}/interviews/{interviewId} [put]
+func (h *Handlers) UpdateInterview(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+	id := c.GetUint(middleware.CtxKeyInterviewID)
+
+	var in jobapplication.BaseInterviewDto
+	if err := c.ShouldBindJSON(&in); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.UpdateInterview(c.Request.Context(), userID, appID, id, in)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"interview": out})
+}
+
+// @Summary Patch an interview
+// @Description Partially update an interview, for example to record its outcome. Only fields provided in the body will be modified.
+// @Tags interview
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   id           path  int                               true  "JobApplication ID"
+// @Param   interviewId  path  int                               true  "Interview ID"
+// @Param   interview    body  jobapplication.InterviewPatchDto  true  "Fields to patch"
+// @Success 200 {object} map[string]jobapplication.InterviewPublicDto "Updated interview"
+// @Failure 400 {object} problem.Problem "Invalid payload"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Interview not found"
+// @Failure 500 {object} problem.Problem "Update failed"
+// @Router /job_application/{id}/interviews/{interviewId} [patch]
+func (h *Handlers) PatchInterview(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+	id := c.GetUint(middleware.CtxKeyInterviewID)
+
+	var patch jobapplication.InterviewPatchDto
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.PatchInterview(c.Request.Context(), userID, appID, id, patch)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"interview": out})
+}
+
+// @Summary Delete an interview
+// @Tags interview
+// @Security BearerAuth
+// @Produce  json
+// @Param   id           path  int  true  "JobApplication ID"
+// @Param   interviewId  path  int  true  "Interview ID"
+// @Success 200 {object} map[string]string "Interview deleted."
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 404 {object} problem.Problem "Interview not found"
+// @Failure 500 {object} problem.Problem "Could not delete interview"
+// @Router /job_application/{id}/interviews/{interviewId} [delete]
+func (h *Handlers) DeleteInterview(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+	appID := c.GetUint(middleware.CtxKeyJobApplicationID)
+	id := c.GetUint(middleware.CtxKeyInterviewID)
+
+	if err := h.Svc.DeleteInterview(c.Request.Context(), userID, appID, id); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Interview deleted successfully"})
+}
@@ -6,16 +6,32 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupJobApplicationRoutes(h *Handlers, requireID gin.HandlerFunc) routes.RouteConfig {
+func SetupJobApplicationRoutes(h *Handlers, requireAuth, requireID, requireInterviewID gin.HandlerFunc) routes.RouteConfig {
     return routes.RouteConfig{
         Prefix: "/job_application",
+        Use:    []gin.HandlerFunc{requireAuth},
         Register: func(g *gin.RouterGroup) {
+            g.GET("", h.ListJobApplications)
             g.POST("", h.CreateJobApplication)
+            g.POST("/import", h.ImportJobApplications)
+            g.GET("/export", h.ExportJobApplications)
+            g.GET("/duplicates", h.GetDuplicates)
             withID := g.Group("/:id", requireID)
             withID.GET("", h.GetJobApplication)
             withID.PUT("", h.UpdateJobApplication)
             withID.PATCH("", h.PatchJobApplication)
             withID.DELETE("", h.DeleteJobApplication)
+            withID.GET("/history", h.GetJobApplicationHistory)
+            withID.GET("/activities", h.ListActivities)
+            withID.POST("/activities", h.CreateActivity)
+
+            withID.GET("/interviews", h.ListInterviews)
+            withID.POST("/interviews", h.CreateInterview)
+            interview := withID.Group("/interviews/:interviewId", requireInterviewID)
+            interview.GET("", h.GetInterview)
+            interview.PUT("", h.UpdateInterview)
+            interview.PATCH("", h.PatchInterview)
+            interview.DELETE("", h.DeleteInterview)
         },
     }
 }
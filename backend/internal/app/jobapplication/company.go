@@ -0,0 +1,46 @@
+package jobapplication
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"appliedTo/internal/app/company"
+	"appliedTo/internal/platform/validate"
+)
+
+// linkCompany points m at the user's company with the given id or, without
+// one, at the company m.Company resolves to (creating it if need be). The
+// application keeps a copy of the company name for display and search: the
+// linked company's, unless m.Company only matched it by domain. An
+// application without a company name is not linked.
+func linkCompany(tx *gorm.DB, userID uint, m *JobApplication, companyID *uint) error {
+	if companyID != nil {
+		c, err := company.Owned(tx, userID, *companyID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return validate.Errors{{Field: "companyId", Message: "unknown company"}}
+		}
+		if err != nil {
+			return err
+		}
+		m.CompanyID, m.Company = &c.ID, c.Name
+		return nil
+	}
+
+	if strings.TrimSpace(m.Company) == "" {
+		m.CompanyID = nil
+		return nil
+	}
+	c, err := company.Resolve(tx, userID, m.Company, m.CompanyURL)
+	if err != nil {
+		return err
+	}
+	m.CompanyID = &c.ID
+	if company.Normalize(m.Company) == c.NormalizedName {
+		m.Company = c.Name
+	} else {
+		m.Company = strings.TrimSpace(m.Company)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package jobapplication
+
+import (
+	"gorm.io/gorm"
+
+	"appliedTo/internal/app/contact"
+)
+
+// linkContact records the contact named by the application's ContactName and
+// ContactEmail in the user's address book and links it to the application
+// and its company. m must have been saved.
+func linkContact(tx *gorm.DB, userID uint, m *JobApplication) error {
+	return contact.LinkApplication(tx, userID, m.ID, m.CompanyID, m.ContactName, m.ContactEmail)
+}
@@ -1,9 +1,17 @@
 package jobapplication
 
-import "time"
+import (
+	"time"
+
+	"appliedTo/internal/app/salary"
+	"appliedTo/internal/platform/validate"
+)
 
 type BaseJobApplicationDto struct {
 	Company        string        `json:"company"`
+	// CompanyID links an existing company; otherwise Company is resolved to
+	// the user's company of that name, which is created if missing.
+	CompanyID      *uint         `json:"companyId,omitempty"`
 	Title          string        `json:"title"`
 	Description    *string       `json:"description,omitempty"`
 	Status         string        `json:"status"`
@@ -13,6 +21,8 @@ type BaseJobApplicationDto struct {
 	LastContactAt  *time.Time    `json:"lastContactAt,omitempty"`
 	PostingURL     *string       `json:"postingUrl,omitempty"`
 	CompanyURL     *string       `json:"companyUrl,omitempty"`
+	// ContactName and ContactEmail name the main contact, who is also added
+	// to the user's contacts and linked to the application.
 	ContactName    *string       `json:"contactName,omitempty"`
 	ContactEmail   *string       `json:"contactEmail,omitempty"`
 	ExternalJobID  *string       `json:"externalJobId,omitempty"`
@@ -29,10 +39,21 @@ type JobApplicationPublicDto struct {
 	ID        uint                   `json:"id"`
 	Created   string                 `json:"created"`
 	BaseJobApplicationDto
+	// SalaryNormalized is the salary range as a yearly amount in the base
+	// currency, or the one requested by salaryCurrency in listings.
+	SalaryNormalized *salary.AnnualDto `json:"salaryNormalized,omitempty"`
+}
+
+type StatusChangePublicDto struct {
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	ChangedBy uint      `json:"changedBy"`
+	ChangedAt time.Time `json:"changedAt"`
 }
 
 type JobApplicationPatchDto struct {
 	Company        *string              `json:"company,omitempty"`
+	CompanyID      *uint                `json:"companyId,omitempty"`
 	Title          *string              `json:"title,omitempty"`
 	Description    *string              `json:"description,omitempty"`
 	Status         *string              `json:"status,omitempty"`
@@ -83,3 +104,203 @@ type SalaryRangePatchDto struct {
 	Period     *string `json:"period,omitempty"`
 	Negotiable *bool   `json:"negotiable,omitempty"`
 }
+
+type BaseInterviewDto struct {
+	Type            string     `json:"type"`
+	ScheduledAt     time.Time  `json:"scheduledAt"`
+	DurationMinutes int        `json:"durationMinutes,omitempty"`
+	Interviewers    []string   `json:"interviewers,omitempty"`
+	Location        *string    `json:"location,omitempty"`
+	MeetingURL      *string    `json:"meetingUrl,omitempty"`
+	Outcome         string     `json:"outcome,omitempty"`
+	Notes           *string    `json:"notes,omitempty"`
+}
+
+type InterviewCreateDto struct {
+	BaseInterviewDto
+	// AdvanceStatus moves an application that has not reached the interview
+	// stage yet to StatusInterview, recording every intermediate transition.
+	AdvanceStatus bool `json:"advanceStatus,omitempty"`
+}
+
+type InterviewPublicDto struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	BaseInterviewDto
+}
+
+type InterviewPatchDto struct {
+	Type            *string    `json:"type,omitempty"`
+	ScheduledAt     *time.Time `json:"scheduledAt,omitempty"`
+	DurationMinutes *int       `json:"durationMinutes,omitempty"`
+	Interviewers    *[]string  `json:"interviewers,omitempty"`
+	Location        *string    `json:"location,omitempty"`
+	MeetingURL      *string    `json:"meetingUrl,omitempty"`
+	Outcome         *string    `json:"outcome,omitempty"`
+	Notes           *string    `json:"notes,omitempty"`
+}
+
+type ActivityCreateDto struct {
+	Type string `json:"type"`
+	// OccurredAt defaults to now.
+	OccurredAt *time.Time `json:"occurredAt,omitempty"`
+	Body       *string    `json:"body,omitempty"`
+}
+
+type ActivityPublicDto struct {
+	ID         uint      `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Body       *string   `json:"body,omitempty"`
+	CreatedBy  uint      `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ActivityListQuery pages through a timeline, newest first.
+type ActivityListQuery struct {
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+type ActivityListDto struct {
+	Items      []ActivityPublicDto `json:"items"`
+	NextCursor *string             `json:"nextCursor,omitempty"`
+}
+
+// JobApplicationFilter narrows a listing of job applications. Multi-valued
+// fields match any of the given values, except Tags which must all be present.
+// Date ranges are inclusive on both ends. SalaryMin and SalaryMax bound the
+// yearly salary normalized to SalaryCurrency (default: the base currency)
+// and exclude applications without a comparable salary.
+type JobApplicationFilter struct {
+	CompanyID        []uint     `form:"companyId"`
+	Status           []string   `form:"status"`
+	Source           []string   `form:"source"`
+	EmploymentType   []string   `form:"employmentType"`
+	WorkLocation     []string   `form:"workLocation"`
+	Tags             []string   `form:"tag"`
+	AppliedFrom      *time.Time `form:"appliedFrom"`
+	AppliedTo        *time.Time `form:"appliedTo"`
+	NextFollowUpFrom *time.Time `form:"nextFollowUpFrom"`
+	NextFollowUpTo   *time.Time `form:"nextFollowUpTo"`
+	LastContactFrom  *time.Time `form:"lastContactFrom"`
+	LastContactTo    *time.Time `form:"lastContactTo"`
+	SalaryMin        *float64   `form:"salaryMin"`
+	SalaryMax        *float64   `form:"salaryMax"`
+	SalaryCurrency   string     `form:"salaryCurrency"`
+}
+
+type JobApplicationListQuery struct {
+	JobApplicationFilter
+	// Q is a full-text query over company, title, description, contact name
+	// and location (web search syntax: quotes, OR, -term).
+	Q string `form:"q"`
+	// Sort is a sortable field name, prefixed with "-" for descending order.
+	// Defaults to "-relevance" when Q is set and "-id" otherwise.
+	Sort   string `form:"sort"`
+	Limit  int    `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+type JobApplicationExportQuery struct {
+	JobApplicationFilter
+	Q      string       `form:"q"`
+	Format ExportFormat `form:"format"`
+}
+
+type JobApplicationListItemDto struct {
+	JobApplicationPublicDto
+	// Rank and Snippet are only set for full-text searches. Snippet is HTML
+	// with the text escaped and matched terms in <mark></mark>.
+	Rank    *float32 `json:"rank,omitempty"`
+	Snippet *string  `json:"snippet,omitempty"`
+}
+
+type JobApplicationListDto struct {
+	Items      []JobApplicationListItemDto `json:"items"`
+	NextCursor *string                     `json:"nextCursor,omitempty"`
+}
+
+// ImportAction is the outcome of one imported row.
+type ImportAction string
+
+const (
+	ImportCreated ImportAction = "created"
+	ImportUpdated ImportAction = "updated"
+	ImportFailed  ImportAction = "failed"
+)
+
+// DuplicateReason names a signal two applications were matched on.
+type DuplicateReason string
+
+const (
+	DuplicatePostingURL DuplicateReason = "postingUrl"
+	DuplicateCompany    DuplicateReason = "company"
+	DuplicateTitle      DuplicateReason = "title"
+	DuplicateLocation   DuplicateReason = "location"
+)
+
+var DuplicateReasons = []DuplicateReason{
+	DuplicatePostingURL, DuplicateCompany, DuplicateTitle, DuplicateLocation,
+}
+
+// JobApplicationCreateQuery holds the options of a create request.
+type JobApplicationCreateQuery struct {
+	// Force creates the application even if it looks like a duplicate.
+	Force bool `form:"force"`
+}
+
+// DuplicateCandidateDto is an existing application that looks like the same
+// job. Reasons lists the signals that matched; it is only set for the
+// candidates of a rejected create.
+type DuplicateCandidateDto struct {
+	ID         uint              `json:"id"`
+	Created    string            `json:"created"`
+	Company    string            `json:"company"`
+	CompanyID  *uint             `json:"companyId,omitempty"`
+	Title      string            `json:"title"`
+	Status     string            `json:"status"`
+	Location   *string           `json:"location,omitempty"`
+	PostingURL *string           `json:"postingUrl,omitempty"`
+	AppliedAt  *time.Time        `json:"appliedAt,omitempty"`
+	Reasons    []DuplicateReason `json:"reasons,omitempty"`
+}
+
+// DuplicateGroupDto is a set of applications that look like the same job,
+// oldest first, with every signal that matched between any two of them.
+type DuplicateGroupDto struct {
+	Reasons []DuplicateReason       `json:"reasons"`
+	Items   []DuplicateCandidateDto `json:"items"`
+}
+
+type DuplicateReportDto struct {
+	Groups []DuplicateGroupDto `json:"groups"`
+}
+
+type JobApplicationImportRowDto struct {
+	// Row is the 1-based position of the record in the input, not counting
+	// the CSV header line.
+	Row           int                  `json:"row"`
+	Action        ImportAction         `json:"action"`
+	ID            *uint                `json:"id,omitempty"`
+	ExternalJobID *string              `json:"externalJobId,omitempty"`
+	Error         *ImportRowErrorDto   `json:"error,omitempty"`
+	// Err is the cause of a failed row, for the handler to translate into
+	// Error.
+	Err error `json:"-"`
+}
+
+type ImportRowErrorDto struct {
+	Code   string                `json:"code"`
+	Title  string                `json:"title"`
+	Errors []validate.FieldError `json:"errors,omitempty"`
+}
+
+type JobApplicationImportReportDto struct {
+	DryRun  bool                         `json:"dryRun"`
+	Created int                          `json:"created"`
+	Updated int                          `json:"updated"`
+	Failed  int                          `json:"failed"`
+	Rows    []JobApplicationImportRowDto `json:"rows"`
+}
@@ -0,0 +1,407 @@
+package jobapplication
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"appliedTo/internal/app/company"
+)
+
+var ErrPossibleDuplicate = errors.New("possible duplicate application")
+
+// DuplicateError rejects a new application that looks like one the user
+// already has. It matches ErrPossibleDuplicate and lists the candidates in
+// its problem document.
+type DuplicateError struct {
+	Candidates []DuplicateCandidateDto
+}
+
+func (e *DuplicateError) Error() string { return ErrPossibleDuplicate.Error() }
+func (e *DuplicateError) Unwrap() error { return ErrPossibleDuplicate }
+
+func (e *DuplicateError) ProblemExtensions() map[string]any {
+	return map[string]any{"duplicates": e.Candidates}
+}
+
+const (
+	// titleSimilarityThreshold is the Dice coefficient from which two titles
+	// at the same company count as the same role.
+	titleSimilarityThreshold = 0.8
+	// maxDuplicateCandidates bounds the applications a new one is compared to.
+	maxDuplicateCandidates = 500
+)
+
+// DUPLICATES
+func (s *Service) Duplicates(ctx context.Context, userID uint) (DuplicateReportDto, error) {
+	var apps []JobApplication
+	if err := s.owned(ctx, userID).
+		Select("id", "created_at", "company", "company_id", "title", "status", "location", "posting_url", "applied_at").
+		Order("id").
+		Find(&apps).Error; err != nil {
+		return DuplicateReportDto{}, err
+	}
+
+	keys := make([]duplicateKey, len(apps))
+	for i := range apps {
+		keys[i] = keyForDuplicates(&apps[i])
+	}
+
+	// Titles are only compared within a company, URLs across all of them.
+	byCompany := map[string][]int{}
+	byURL := map[string][]int{}
+	for i, k := range keys {
+		if k.company != "" {
+			byCompany[k.company] = append(byCompany[k.company], i)
+		}
+		if k.url != "" {
+			byURL[k.url] = append(byURL[k.url], i)
+		}
+	}
+
+	groups := newDisjointSet(len(apps))
+	reasons := map[[2]int][]DuplicateReason{}
+	link := func(i, j int) {
+		if _, seen := reasons[[2]int{i, j}]; seen {
+			return
+		}
+		if r, _, ok := matchDuplicate(keys[i], keys[j]); ok {
+			reasons[[2]int{i, j}] = r
+			groups.union(i, j)
+		}
+	}
+	for _, idx := range byCompany {
+		for a := range idx {
+			for b := a + 1; b < len(idx); b++ {
+				link(idx[a], idx[b])
+			}
+		}
+	}
+	for _, idx := range byURL {
+		for a := range idx {
+			for b := a + 1; b < len(idx); b++ {
+				link(idx[a], idx[b])
+			}
+		}
+	}
+
+	byRoot := map[int]*DuplicateGroupDto{}
+	var roots []int
+	for i := range apps {
+		root := groups.find(i)
+		if groups.size[root] < 2 {
+			continue
+		}
+		g, ok := byRoot[root]
+		if !ok {
+			g = &DuplicateGroupDto{}
+			byRoot[root] = g
+			roots = append(roots, root)
+		}
+		g.Items = append(g.Items, MapDuplicateCandidateToDto(apps[i]))
+	}
+	for pair, r := range reasons {
+		g := byRoot[groups.find(pair[0])]
+		g.Reasons = mergeReasons(g.Reasons, r)
+	}
+
+	out := DuplicateReportDto{Groups: make([]DuplicateGroupDto, 0, len(roots))}
+	for _, root := range roots {
+		out.Groups = append(out.Groups, *byRoot[root])
+	}
+	return out, nil
+}
+
+// -------- helpers --------
+
+// findDuplicates returns the user's applications that look like m, best
+// matches first. m must already be linked to its company.
+func findDuplicates(tx *gorm.DB, userID uint, m *JobApplication) ([]DuplicateCandidateDto, error) {
+	key := keyForDuplicates(m)
+
+	q := tx.Where("user_id = ?", userID)
+	switch {
+	case m.CompanyID != nil && key.host != "":
+		q = q.Where("company_id = ? OR posting_url ILIKE ?", *m.CompanyID, likeContains(key.host))
+	case m.CompanyID != nil:
+		q = q.Where("company_id = ?", *m.CompanyID)
+	case key.host != "":
+		q = q.Where("posting_url ILIKE ?", likeContains(key.host))
+	default:
+		return nil, nil
+	}
+
+	var others []JobApplication
+	if err := q.
+		Select("id", "created_at", "company", "company_id", "title", "status", "location", "posting_url", "applied_at").
+		Order("id DESC").
+		Limit(maxDuplicateCandidates).
+		Find(&others).Error; err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		dto DuplicateCandidateDto
+		url bool
+		sim float64
+	}
+	var found []scored
+	for i := range others {
+		reasons, sim, ok := matchDuplicate(key, keyForDuplicates(&others[i]))
+		if !ok {
+			continue
+		}
+		dto := MapDuplicateCandidateToDto(others[i])
+		dto.Reasons = reasons
+		found = append(found, scored{dto: dto, url: hasReason(reasons, DuplicatePostingURL), sim: sim})
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].url != found[j].url {
+			return found[i].url
+		}
+		return found[i].sim > found[j].sim
+	})
+
+	out := make([]DuplicateCandidateDto, len(found))
+	for i, f := range found {
+		out[i] = f.dto
+	}
+	return out, nil
+}
+
+// duplicateKey holds the normalized fields applications are compared by.
+type duplicateKey struct {
+	companyID *uint
+	company   string
+	title     string
+	location  string
+	url       string
+	host      string
+}
+
+func keyForDuplicates(m *JobApplication) duplicateKey {
+	k := duplicateKey{
+		companyID: m.CompanyID,
+		company:   company.Normalize(m.Company),
+		title:     normalizeTitle(m.Title),
+	}
+	if m.Location != nil {
+		k.location = strings.Join(strings.Fields(nonAlnum.ReplaceAllString(strings.ToLower(*m.Location), " ")), " ")
+	}
+	if m.PostingURL != nil {
+		k.url, k.host = canonicalURL(*m.PostingURL)
+	}
+	return k
+}
+
+// matchDuplicate reports whether a and b look like the same job: either
+// their posting URLs are the same once tracking parameters are dropped, or
+// they are at the same company with similar titles and compatible locations.
+func matchDuplicate(a, b duplicateKey) ([]DuplicateReason, float64, bool) {
+	var reasons []DuplicateReason
+	sameURL := a.url != "" && a.url == b.url
+	if sameURL {
+		reasons = append(reasons, DuplicatePostingURL)
+	}
+
+	sameCompany := (a.companyID != nil && b.companyID != nil && *a.companyID == *b.companyID) ||
+		(a.company != "" && a.company == b.company)
+	if sameCompany {
+		reasons = append(reasons, DuplicateCompany)
+	}
+	sim := titleSimilarity(a.title, b.title)
+	if sim >= titleSimilarityThreshold {
+		reasons = append(reasons, DuplicateTitle)
+	}
+	if a.location != "" && a.location == b.location {
+		reasons = append(reasons, DuplicateLocation)
+	}
+
+	similar := sameCompany && sim >= titleSimilarityThreshold && locationsCompatible(a.location, b.location)
+	return reasons, sim, sameURL || similar
+}
+
+// locationsCompatible treats a missing location as unknown and "Berlin" as
+// compatible with "Berlin, Germany".
+func locationsCompatible(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	return strings.Contains(a, b) || strings.Contains(b, a)
+}
+
+var nonAlnum = regexp.MustCompile(`[^\pL\pN]+`)
+
+// titleAbbreviations are expanded before titles are compared.
+var titleAbbreviations = map[string]string{
+	"sr": "senior", "snr": "senior", "jr": "junior", "jnr": "junior",
+	"eng": "engineer", "engr": "engineer", "dev": "developer",
+	"mgr": "manager", "swe": "software engineer", "sde": "software engineer",
+	"fe": "frontend", "be": "backend", "fs": "fullstack",
+}
+
+// normalizeTitle lower-cases a job title, expands common abbreviations and
+// drops single letters such as the gender markers in "(m/w/d)". Words are
+// sorted so that "Engineer, Backend" matches "Backend Engineer".
+func normalizeTitle(title string) string {
+	var words []string
+	for _, w := range strings.Fields(nonAlnum.ReplaceAllString(strings.ToLower(title), " ")) {
+		if len([]rune(w)) == 1 {
+			continue
+		}
+		if full, ok := titleAbbreviations[w]; ok {
+			w = full
+		}
+		words = append(words, strings.Fields(w)...)
+	}
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// titleSimilarity is the Dice coefficient of the character bigrams of two
+// normalized titles, from 0 (nothing in common) to 1 (identical).
+func titleSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ga, gb := bigrams(a), bigrams(b)
+	total := 0
+	for _, n := range ga {
+		total += n
+	}
+	for _, n := range gb {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	shared := 0
+	for g, n := range ga {
+		shared += min(n, gb[g])
+	}
+	return 2 * float64(shared) / float64(total)
+}
+
+func bigrams(s string) map[string]int {
+	r := []rune(s)
+	out := make(map[string]int, len(r))
+	for i := 0; i+1 < len(r); i++ {
+		out[string(r[i:i+2])]++
+	}
+	return out
+}
+
+// trackingParams are query parameters that ad networks, newsletters and job
+// boards append to posting links. Parameters starting with "utm_" are
+// dropped as well. Generic names such as "ref" or "source" are kept, since
+// some boards identify the posting by them.
+var trackingParams = map[string]bool{
+	"gclid": true, "fbclid": true, "msclkid": true, "yclid": true, "igshid": true,
+	"mc_cid": true, "mc_eid": true, "_hsenc": true, "_hsmi": true,
+	// LinkedIn
+	"refid": true, "trk": true, "trkinfo": true, "trackingid": true,
+	// Greenhouse and Lever
+	"gh_src": true, "lever-source": true, "lever-origin": true,
+}
+
+// canonicalURL returns the key posting URLs are compared by, ignoring the
+// scheme, a leading "www.", a trailing slash, the fragment, tracking
+// parameters and the order of the remaining parameters, along with its host.
+// Both are empty if raw is not an absolute URL.
+func canonicalURL(raw string) (key, host string) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return "", ""
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Hostname() == "" {
+		return "", ""
+	}
+	host = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	query := u.Query()
+	for name := range query {
+		lower := strings.ToLower(name)
+		if trackingParams[lower] || strings.HasPrefix(lower, "utm_") {
+			query.Del(name)
+		}
+	}
+
+	key = host + strings.TrimRight(u.EscapedPath(), "/")
+	if q := query.Encode(); q != "" {
+		key += "?" + q
+	}
+	return key, host
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func likeContains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+func hasReason(reasons []DuplicateReason, r DuplicateReason) bool {
+	for _, x := range reasons {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeReasons adds the reasons of add missing from into, keeping the order
+// of DuplicateReasons.
+func mergeReasons(into, add []DuplicateReason) []DuplicateReason {
+	var out []DuplicateReason
+	for _, r := range DuplicateReasons {
+		if hasReason(into, r) || hasReason(add, r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// disjointSet groups application indexes into clusters of duplicates.
+type disjointSet struct {
+	parent []int
+	size   []int
+}
+
+func newDisjointSet(n int) *disjointSet {
+	d := &disjointSet{parent: make([]int, n), size: make([]int, n)}
+	for i := range d.parent {
+		d.parent[i], d.size[i] = i, 1
+	}
+	return d
+}
+
+func (d *disjointSet) find(i int) int {
+	for d.parent[i] != i {
+		d.parent[i] = d.parent[d.parent[i]]
+		i = d.parent[i]
+	}
+	return i
+}
+
+func (d *disjointSet) union(a, b int) {
+	a, b = d.find(a), d.find(b)
+	if a == b {
+		return
+	}
+	if d.size[a] < d.size[b] {
+		a, b = b, a
+	}
+	d.parent[b] = a
+	d.size[a] += d.size[b]
+}
@@ -0,0 +1,203 @@
+package jobapplication
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantKey  string
+		wantHost string
+	}{
+		{
+			name:     "LinkedIn tracking",
+			raw:      "https://www.linkedin.com/jobs/view/3791234567/?refId=8f2a%3D%3D&trackingId=Qx1%2Bw%3D%3D&trk=public_jobs_topcard-title",
+			wantKey:  "linkedin.com/jobs/view/3791234567",
+			wantHost: "linkedin.com",
+		},
+		{
+			name:     "Greenhouse source",
+			raw:      "https://boards.greenhouse.io/acme/jobs/4567890?gh_src=6a1b2c3d4us",
+			wantKey:  "boards.greenhouse.io/acme/jobs/4567890",
+			wantHost: "boards.greenhouse.io",
+		},
+		{
+			name:     "Lever source and origin",
+			raw:      "https://jobs.lever.co/acme/0d3c1b2a-5e6f-4a7b-8c9d-0e1f2a3b4c5d/apply?lever-source=LinkedIn&lever-origin=applied",
+			wantKey:  "jobs.lever.co/acme/0d3c1b2a-5e6f-4a7b-8c9d-0e1f2a3b4c5d/apply",
+			wantHost: "jobs.lever.co",
+		},
+		{
+			name:     "Indeed keeps the job key",
+			raw:      "https://de.indeed.com/viewjob?jk=1a2b3c4d5e6f7a8b&utm_campaign=job_alerts&utm_source=newsletter",
+			wantKey:  "de.indeed.com/viewjob?jk=1a2b3c4d5e6f7a8b",
+			wantHost: "de.indeed.com",
+		},
+		{
+			name:     "generic parameters are kept",
+			raw:      "https://careers.example.com/job?ref=JR-1042&source=12&from=emea&origin=berlin",
+			wantKey:  "careers.example.com/job?from=emea&origin=berlin&ref=JR-1042&source=12",
+			wantHost: "careers.example.com",
+		},
+		{
+			name:     "scheme, www, slash, fragment and parameter order",
+			raw:      "  http://WWW.Example.com/jobs/42/?b=2&a=1#apply ",
+			wantKey:  "example.com/jobs/42?a=1&b=2",
+			wantHost: "example.com",
+		},
+		{
+			name:     "missing scheme",
+			raw:      "jobs.example.com/42",
+			wantKey:  "jobs.example.com/42",
+			wantHost: "jobs.example.com",
+		},
+		{name: "empty", raw: "  "},
+		{name: "not a URL", raw: "https://"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, host := canonicalURL(tt.raw)
+			if key != tt.wantKey || host != tt.wantHost {
+				t.Errorf("canonicalURL(%q) = %q, %q, want %q, %q", tt.raw, key, host, tt.wantKey, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestCanonicalURLKeepsPostingsApart(t *testing.T) {
+	pairs := [][2]string{
+		{"https://de.indeed.com/viewjob?jk=1a2b3c4d5e6f7a8b", "https://de.indeed.com/viewjob?jk=9f8e7d6c5b4a3f2e"},
+		{"https://careers.example.com/job?ref=JR-1042", "https://careers.example.com/job?ref=JR-2001"},
+		{"https://careers.example.com/apply?source=12", "https://careers.example.com/apply?source=13"},
+	}
+	for _, p := range pairs {
+		a, _ := canonicalURL(p[0])
+		b, _ := canonicalURL(p[1])
+		if a == b {
+			t.Errorf("%s and %s both map to %q", p[0], p[1], a)
+		}
+	}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		{"Backend Engineer", "backend engineer"},
+		{"Engineer, Backend", "backend engineer"},
+		{"Sr. Backend Eng (m/w/d)", "backend engineer senior"},
+		{"SWE", "engineer software"},
+		{"  Jr   FE Dev ", "developer frontend junior"},
+		{"Entwickler*in (w/m/d)", "entwickler in"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeTitle(tt.title); got != tt.want {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		similar bool
+	}{
+		{"Senior Software Engineer", "Sr. SWE", true},
+		{"Backend Engineer", "Engineer - Backend", true},
+		{"Platform Engineer", "Platform Engineers", true},
+		{"Backend Developer", "Frontend Developer", false},
+		{"Data Engineer", "Data Scientist", false},
+		{"Product Manager", "", false},
+	}
+	for _, tt := range tests {
+		got := titleSimilarity(normalizeTitle(tt.a), normalizeTitle(tt.b))
+		if similar := got >= titleSimilarityThreshold; similar != tt.similar {
+			t.Errorf("titleSimilarity(%q, %q) = %.3f, want similar = %v", tt.a, tt.b, got, tt.similar)
+		}
+		if rev := titleSimilarity(normalizeTitle(tt.b), normalizeTitle(tt.a)); math.Abs(rev-got) > 1e-9 {
+			t.Errorf("titleSimilarity is not symmetric for %q, %q: %.3f vs %.3f", tt.a, tt.b, got, rev)
+		}
+	}
+	if got := titleSimilarity("backend engineer", "backend engineer"); got != 1 {
+		t.Errorf("titleSimilarity of equal titles = %.3f, want 1", got)
+	}
+	if got := titleSimilarity("", ""); got != 0 {
+		t.Errorf("titleSimilarity of empty titles = %.3f, want 0", got)
+	}
+}
+
+func TestMatchDuplicate(t *testing.T) {
+	id := func(n uint) *uint { return &n }
+	str := func(s string) *string { return &s }
+	app := func(companyID *uint, companyName, title string, location, postingURL *string) duplicateKey {
+		return keyForDuplicates(&JobApplication{
+			CompanyID: companyID, Company: companyName, Title: title, Location: location, PostingURL: postingURL,
+		})
+	}
+
+	tests := []struct {
+		name        string
+		a, b        duplicateKey
+		want        bool
+		wantReasons []DuplicateReason
+	}{
+		{
+			name:        "same posting, tracking aside",
+			a:           app(nil, "Acme", "Backend Engineer", nil, str("https://boards.greenhouse.io/acme/jobs/4567890?gh_src=abc")),
+			b:           app(nil, "Initech", "Platform Engineer", nil, str("boards.greenhouse.io/acme/jobs/4567890")),
+			want:        true,
+			wantReasons: []DuplicateReason{DuplicatePostingURL},
+		},
+		{
+			name:        "same company and similar title",
+			a:           app(id(1), "Acme", "Sr. Backend Eng (m/w/d)", str("Berlin"), nil),
+			b:           app(id(1), "Acme", "Senior Backend Engineer", str("Berlin, Germany"), nil),
+			want:        true,
+			wantReasons: []DuplicateReason{DuplicateCompany, DuplicateTitle},
+		},
+		{
+			name:        "same company, title and location",
+			a:           app(id(1), "Acme", "Backend Engineer", str("Berlin"), nil),
+			b:           app(id(1), "Acme", "Backend Engineer", str("Berlin"), nil),
+			want:        true,
+			wantReasons: []DuplicateReason{DuplicateCompany, DuplicateTitle, DuplicateLocation},
+		},
+		{
+			name:        "same title in another city",
+			a:           app(id(1), "Acme", "Backend Engineer", str("Berlin"), nil),
+			b:           app(id(1), "Acme", "Backend Engineer", str("Munich"), nil),
+			want:        false,
+			wantReasons: []DuplicateReason{DuplicateCompany, DuplicateTitle},
+		},
+		{
+			name:        "same title at another company",
+			a:           app(id(1), "Acme", "Backend Engineer", nil, nil),
+			b:           app(id(2), "Globex", "Backend Engineer", nil, nil),
+			want:        false,
+			wantReasons: []DuplicateReason{DuplicateTitle},
+		},
+		{
+			name:        "different postings on the same board",
+			a:           app(nil, "", "Backend Engineer", nil, str("https://de.indeed.com/viewjob?jk=1a2b3c4d5e6f7a8b")),
+			b:           app(nil, "", "Backend Engineer", nil, str("https://de.indeed.com/viewjob?jk=9f8e7d6c5b4a3f2e")),
+			want:        false,
+			wantReasons: []DuplicateReason{DuplicateTitle},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons, _, ok := matchDuplicate(tt.a, tt.b)
+			if ok != tt.want {
+				t.Errorf("matchDuplicate() = %v, want %v", ok, tt.want)
+			}
+			if !slices.Equal(reasons, tt.wantReasons) {
+				t.Errorf("reasons = %v, want %v", reasons, tt.wantReasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,493 @@
+package jobapplication
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"appliedTo/internal/app/salary"
+	"appliedTo/internal/utils"
+)
+
+var ErrInvalidExportFormat = errors.New("invalid export format")
+
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportJSON ExportFormat = "json"
+	ExportICS  ExportFormat = "ics"
+)
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportCSV:
+		return "text/csv; charset=utf-8"
+	case ExportICS:
+		return "text/calendar; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// ExportWriter encodes exported applications one at a time. Nothing is
+// written before the first Write or Close, so a failing query can still be
+// reported as an error response.
+type ExportWriter interface {
+	Write(m *JobApplication) error
+	// Close writes whatever closes the document and flushes it.
+	Close() error
+}
+
+// interviewExportWriter is implemented by export writers that also render the
+// interviews of the exported applications.
+type interviewExportWriter interface {
+	WriteInterview(m *JobApplication, iv *Interview) error
+}
+
+// salaryExportWriter is implemented by export writers that include the
+// normalized salary of each application.
+type salaryExportWriter interface {
+	useSalaries(n salary.Normalizer)
+}
+
+// NewExportWriter returns the writer for format, which defaults to JSON.
+func NewExportWriter(format ExportFormat, w io.Writer) (ExportWriter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvExportWriter{w: csv.NewWriter(w)}, nil
+	case ExportJSON, "":
+		return &jsonExportWriter{w: w}, nil
+	case ExportICS:
+		return &icsExportWriter{w: w, stamp: time.Now().UTC()}, nil
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+}
+
+// EXPORT
+// Export streams the user's applications matching in to w, oldest first,
+// without loading them all into memory. Writers that render interviews get
+// the interviews of those applications afterwards.
+func (s *Service) Export(ctx context.Context, userID uint, in JobApplicationExportQuery, w ExportWriter) error {
+	n, err := s.salaries.Normalizer(ctx, in.SalaryCurrency)
+	if err != nil {
+		return err
+	}
+	q := applyFilter(s.owned(ctx, userID).Model(&JobApplication{}), in.JobApplicationFilter, n)
+	if search := strings.TrimSpace(in.Q); search != "" {
+		q = q.Where("search_vector @@ "+searchQuery, search)
+	}
+
+	if sw, ok := w.(salaryExportWriter); ok {
+		sw.useSalaries(n)
+	}
+	if err := exportApplications(q, w); err != nil {
+		return err
+	}
+	if iw, ok := w.(interviewExportWriter); ok {
+		if err := s.exportInterviews(ctx, q, iw); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func exportApplications(q *gorm.DB, w ExportWriter) error {
+	rows, err := q.Order("id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m JobApplication
+		if err := q.ScanRows(rows, &m); err != nil {
+			return err
+		}
+		if err := w.Write(&m); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// interviewRow is an interview together with the application fields its
+// calendar event refers to.
+type interviewRow struct {
+	Interview
+	AppCompany    string
+	AppTitle      string
+	AppPostingURL *string
+}
+
+// exportInterviews streams the interviews of the applications selected by
+// apps, in schedule order.
+func (s *Service) exportInterviews(ctx context.Context, apps *gorm.DB, w interviewExportWriter) error {
+	q := s.db.WithContext(ctx).Model(&Interview{}).
+		Select("interviews.*, ja.company AS app_company, ja.title AS app_title, ja.posting_url AS app_posting_url").
+		Joins("JOIN (?) AS ja ON ja.id = interviews.job_application_id",
+			apps.Session(&gorm.Session{}).Select("id", "company", "title", "posting_url"))
+
+	rows, err := q.Order("interviews.scheduled_at, interviews.id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r interviewRow
+		if err := q.ScanRows(rows, &r); err != nil {
+			return err
+		}
+		app := JobApplication{Company: r.AppCompany, Title: r.AppTitle, PostingURL: r.AppPostingURL}
+		app.ID = r.JobApplicationID
+		if err := w.WriteInterview(&app, &r.Interview); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ---- CSV ----
+
+// csvColumns are the exported CSV columns. Apart from id and created they are
+// the field paths ParseCSV understands, so an export can be imported again
+// without a mapping.
+var csvColumns = []struct {
+	name  string
+	value func(m *JobApplication) string
+}{
+	{"id", func(m *JobApplication) string { return strconv.FormatUint(uint64(m.ID), 10) }},
+	{"created", func(m *JobApplication) string { return m.CreatedAt.UTC().Format(time.RFC3339) }},
+	{"company", func(m *JobApplication) string { return m.Company }},
+	{"title", func(m *JobApplication) string { return m.Title }},
+	{"description", func(m *JobApplication) string { return deref(m.Description) }},
+	{"status", func(m *JobApplication) string { return string(m.Status) }},
+	{"source", func(m *JobApplication) string { return string(m.Source) }},
+	{"appliedAt", func(m *JobApplication) string { return csvTime(m.AppliedAt) }},
+	{"nextFollowUpAt", func(m *JobApplication) string { return csvTime(m.NextFollowUpAt) }},
+	{"lastContactAt", func(m *JobApplication) string { return csvTime(m.LastContactAt) }},
+	{"postingUrl", func(m *JobApplication) string { return deref(m.PostingURL) }},
+	{"companyUrl", func(m *JobApplication) string { return deref(m.CompanyURL) }},
+	{"contactName", func(m *JobApplication) string { return deref(m.ContactName) }},
+	{"contactEmail", func(m *JobApplication) string { return deref(m.ContactEmail) }},
+	{"externalJobId", func(m *JobApplication) string { return deref(m.ExternalJobID) }},
+	{"location", func(m *JobApplication) string { return deref(m.Location) }},
+	{"tags", func(m *JobApplication) string {
+		tags, _ := utils.FromJSONTags(m.Tags)
+		return strings.Join(tags, ";")
+	}},
+	{"employment.type", func(m *JobApplication) string { return string(m.Employment.Type) }},
+	{"employment.duration", func(m *JobApplication) string { return deref(m.Employment.Duration) }},
+	{"employment.workLocation", func(m *JobApplication) string { return string(m.Employment.WorkLocation) }},
+	{"employment.seniority", func(m *JobApplication) string { return deref(m.Employment.Seniority) }},
+	{"employment.hoursPerWeek", func(m *JobApplication) string {
+		if m.Employment.HoursPerWeek == nil {
+			return ""
+		}
+		return strconv.Itoa(*m.Employment.HoursPerWeek)
+	}},
+	{"employment.salaryRange.from", salaryColumn(func(sr *SalaryRange) string { return strconv.Itoa(sr.From) })},
+	{"employment.salaryRange.to", salaryColumn(func(sr *SalaryRange) string { return strconv.Itoa(sr.To) })},
+	{"employment.salaryRange.currency", salaryColumn(func(sr *SalaryRange) string { return sr.Currency })},
+	{"employment.salaryRange.period", salaryColumn(func(sr *SalaryRange) string { return string(sr.Period) })},
+	{"employment.salaryRange.negotiable", salaryColumn(func(sr *SalaryRange) string { return strconv.FormatBool(sr.Negotiable) })},
+}
+
+type csvExportWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (e *csvExportWriter) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	names := make([]string, len(csvColumns))
+	for i, c := range csvColumns {
+		names[i] = c.name
+	}
+	return e.w.Write(names)
+}
+
+func (e *csvExportWriter) Write(m *JobApplication) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	record := make([]string, len(csvColumns))
+	for i, c := range csvColumns {
+		record[i] = c.value(m)
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExportWriter) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func salaryColumn(f func(sr *SalaryRange) string) func(m *JobApplication) string {
+	return func(m *JobApplication) string {
+		if m.Employment.SalaryRange == nil {
+			return ""
+		}
+		return f(m.Employment.SalaryRange)
+	}
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// ---- JSON ----
+
+// jsonExportWriter writes a JSON array of JobApplicationPublicDto.
+type jsonExportWriter struct {
+	w        io.Writer
+	n        int
+	salaries *salary.Normalizer
+}
+
+func (e *jsonExportWriter) useSalaries(n salary.Normalizer) { e.salaries = &n }
+
+func (e *jsonExportWriter) Write(m *JobApplication) error {
+	out := MapModelToPublicDto(*m)
+	if e.salaries != nil {
+		out.SalaryNormalized = normalizedSalary(m, *e.salaries)
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if e.n == 0 {
+		sep = "[\n"
+	}
+	e.n++
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+func (e *jsonExportWriter) Close() error {
+	end := "\n]\n"
+	if e.n == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+// ---- iCalendar ----
+
+// calendarEvent is one VEVENT derived from a job application.
+type calendarEvent struct {
+	uid         string
+	start       time.Time
+	duration    time.Duration
+	summary     string
+	description string
+	location    string
+	url         string
+}
+
+// calendarEvents returns the calendar entries of an application.
+func calendarEvents(m *JobApplication) []calendarEvent {
+	var events []calendarEvent
+	if m.NextFollowUpAt != nil {
+		events = append(events, calendarEvent{
+			uid:         fmt.Sprintf("follow-up-%d@appliedto", m.ID),
+			start:       *m.NextFollowUpAt,
+			duration:    30 * time.Minute,
+			summary:     "Follow up: " + applicationLabel(m),
+			description: fmt.Sprintf("Status: %s", m.Status),
+			url:         deref(m.PostingURL),
+		})
+	}
+	return events
+}
+
+// interviewEvent returns the calendar entry of an interview round.
+func interviewEvent(m *JobApplication, iv *Interview) calendarEvent {
+	var desc []string
+	if interviewers, _ := utils.FromJSONTags(iv.Interviewers); len(interviewers) > 0 {
+		desc = append(desc, "Interviewers: "+strings.Join(interviewers, ", "))
+	}
+	if iv.MeetingURL != nil {
+		desc = append(desc, "Meeting: "+*iv.MeetingURL)
+	}
+	if iv.Notes != nil {
+		desc = append(desc, *iv.Notes)
+	}
+	link := deref(iv.MeetingURL)
+	if link == "" {
+		link = deref(m.PostingURL)
+	}
+	return calendarEvent{
+		uid:         fmt.Sprintf("interview-%d@appliedto", iv.ID),
+		start:       iv.ScheduledAt,
+		duration:    time.Duration(iv.DurationMinutes) * time.Minute,
+		summary:     fmt.Sprintf("%s interview: %s", iv.Type, applicationLabel(m)),
+		description: strings.Join(desc, "\n"),
+		location:    deref(iv.Location),
+		url:         link,
+	}
+}
+
+func applicationLabel(m *JobApplication) string {
+	if m.Company == "" {
+		return m.Title
+	}
+	return m.Title + " at " + m.Company
+}
+
+// icsExportWriter writes an RFC 5545 calendar with the events of every
+// application.
+type icsExportWriter struct {
+	w      io.Writer
+	stamp  time.Time
+	opened bool
+}
+
+func (e *icsExportWriter) open() error {
+	if e.opened {
+		return nil
+	}
+	e.opened = true
+	return e.lines(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//AppliedTo//Job applications//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	)
+}
+
+func (e *icsExportWriter) Write(m *JobApplication) error {
+	if err := e.open(); err != nil {
+		return err
+	}
+	for _, ev := range calendarEvents(m) {
+		if err := e.event(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *icsExportWriter) WriteInterview(m *JobApplication, iv *Interview) error {
+	if err := e.open(); err != nil {
+		return err
+	}
+	if iv.Outcome == OutcomeCancelled {
+		return nil
+	}
+	return e.event(interviewEvent(m, iv))
+}
+
+func (e *icsExportWriter) event(ev calendarEvent) error {
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + ev.uid,
+		"DTSTAMP:" + icsTime(e.stamp),
+		"DTSTART:" + icsTime(ev.start),
+		"DTEND:" + icsTime(ev.start.Add(ev.duration)),
+		"SUMMARY:" + icsText(ev.summary),
+	}
+	if ev.description != "" {
+		lines = append(lines, "DESCRIPTION:"+icsText(ev.description))
+	}
+	if ev.location != "" {
+		lines = append(lines, "LOCATION:"+icsText(ev.location))
+	}
+	if u := icsURL(ev.url); u != "" {
+		lines = append(lines, "URL:"+u)
+	}
+	return e.lines(append(lines, "END:VEVENT")...)
+}
+
+func (e *icsExportWriter) Close() error {
+	if err := e.open(); err != nil {
+		return err
+	}
+	return e.lines("END:VCALENDAR")
+}
+
+func (e *icsExportWriter) lines(lines ...string) error {
+	for _, l := range lines {
+		if _, err := io.WriteString(e.w, icsFold(l)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func icsText(s string) string {
+	return icsEscaper.Replace(s)
+}
+
+// icsURL returns raw as the value of a URL property, or "" unless it is an
+// absolute http(s) URL. Posting URLs are not validated on input, and
+// url.Parse rejects the control characters that would end the line.
+func icsURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+// icsFold splits a content line into chunks of at most 75 octets, without
+// breaking UTF-8 sequences, as RFC 5545 section 3.1 requires.
+func icsFold(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	b.WriteString(line)
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package jobapplication
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICSURL(t *testing.T) {
+	tests := []struct {
+		raw, want string
+	}{
+		{"https://boards.greenhouse.io/acme/jobs/4567890", "https://boards.greenhouse.io/acme/jobs/4567890"},
+		{" http://example.com/jobs?id=1 ", "http://example.com/jobs?id=1"},
+		{"https://example.com/a b", "https://example.com/a%20b"},
+		{"https://example.com/\r\nBEGIN:VEVENT", ""},
+		{"https://example.com/\rX", ""},
+		{"jobs.example.com/42", ""},
+		{"javascript:alert(1)", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := icsURL(tt.raw); got != tt.want {
+			t.Errorf("icsURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestICSEventCannotInjectLines(t *testing.T) {
+	var b strings.Builder
+	e := &icsExportWriter{w: &b, stamp: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	evil := "https://example.com/\r\nEND:VEVENT\r\nBEGIN:VEVENT"
+	followUp := time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)
+	m := &JobApplication{Title: "Engineer\rX", Company: "Acme", PostingURL: &evil, NextFollowUpAt: &followUp}
+	m.ID = 1
+	if err := e.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != 1 {
+		t.Errorf("calendar has %d events, want 1:\n%s", n, out)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if strings.ContainsAny(line, "\r\n") {
+			t.Errorf("line %q holds a line break", line)
+		}
+		if strings.HasPrefix(line, "URL:") {
+			t.Errorf("invalid posting URL was written: %q", line)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package jobapplication
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxImportRows caps the number of records a single import may contain.
+const MaxImportRows = 1000
+
+var (
+	ErrEmptyImport    = errors.New("nothing to import")
+	ErrImportTooLarge = errors.New("too many rows to import")
+
+	// errDryRun rolls back the transaction of a row in a dry run.
+	errDryRun = errors.New("dry run")
+)
+
+// ImportRow is one record of a bulk import. Err is set when the record could
+// not be decoded; such rows are reported as failed without touching the
+// database.
+type ImportRow struct {
+	Row int
+	In  JobApplicationCreateDto
+	Err error
+}
+
+// importKey identifies an application by the uniq_user_extid_src index.
+type importKey struct {
+	source        ApplicationSource
+	externalJobID string
+}
+
+// Import creates or updates one application per row. Rows with an external
+// job ID update the user's application with the same source and external ID
+// if there is one (restoring it if it was deleted); all other rows create a
+// new application. Every row is validated like Create and Update and runs in
+// its own transaction, so a failing row does not affect the others.
+//
+// In a dry run every row is written and then rolled back, which also checks
+// database constraints. A later row that repeats the key of an earlier one is
+// reported as an update, as it would be in a real run.
+func (s *Service) Import(ctx context.Context, userID uint, rows []ImportRow, dryRun bool) (JobApplicationImportReportDto, error) {
+	report := JobApplicationImportReportDto{DryRun: dryRun}
+	if len(rows) == 0 {
+		return report, ErrEmptyImport
+	}
+	if len(rows) > MaxImportRows {
+		return report, ErrImportTooLarge
+	}
+
+	seen := make(map[importKey]bool)
+	report.Rows = make([]JobApplicationImportRowDto, 0, len(rows))
+	for _, r := range rows {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		res := JobApplicationImportRowDto{Row: r.Row, ExternalJobID: r.In.ExternalJobID, Err: r.Err}
+		if res.Err == nil {
+			var m JobApplication
+			m, res.Action, res.Err = s.importRow(ctx, userID, r.In, dryRun)
+			// IDs of applications created in a dry run were rolled back.
+			if res.Err == nil && (!dryRun || res.Action == ImportUpdated) {
+				res.ID = &m.ID
+			}
+			if key, ok := keyOf(&m); ok && res.Err == nil {
+				if seen[key] {
+					res.Action = ImportUpdated
+				}
+				seen[key] = true
+			}
+		}
+
+		switch {
+		case res.Err != nil:
+			res.Action = ImportFailed
+			report.Failed++
+		case res.Action == ImportCreated:
+			report.Created++
+		default:
+			report.Updated++
+		}
+		report.Rows = append(report.Rows, res)
+	}
+	return report, nil
+}
+
+func (s *Service) importRow(ctx context.Context, userID uint, in JobApplicationCreateDto, dryRun bool) (JobApplication, ImportAction, error) {
+	var (
+		m      JobApplication
+		action = ImportCreated
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		found, err := findImportTarget(tx, userID, in, &m)
+		if err != nil {
+			return err
+		}
+
+		from := m.Status
+		OverwriteModel(&m, in)
+		if found {
+			action = ImportUpdated
+			// Spreadsheets rarely track the status; keep the current one.
+			if m.Status == "" {
+				m.Status = from
+			}
+		} else {
+			m.UserID = userID
+			if m.Status == "" {
+				m.Status = StatusApplied
+			}
+		}
+		if err := validateModel(&m); err != nil {
+			return err
+		}
+
+		if found {
+			if err := checkTransition(from, m.Status); err != nil {
+				return err
+			}
+		}
+		if err := linkCompany(tx, userID, &m, in.CompanyID); err != nil {
+			return err
+		}
+
+		if found {
+			m.DeletedAt = gorm.DeletedAt{}
+			err = tx.Unscoped().Save(&m).Error
+		} else {
+			err = tx.Create(&m).Error
+		}
+		if err != nil {
+			return err
+		}
+		if err := linkContact(tx, userID, &m); err != nil {
+			return err
+		}
+		if err := recordStatusChange(tx, userID, &m, from); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return m, action, err
+}
+
+// findImportTarget loads the application in matches by source and external
+// job ID into m, including a soft-deleted one, which still holds its key.
+func findImportTarget(tx *gorm.DB, userID uint, in JobApplicationCreateDto, m *JobApplication) (bool, error) {
+	if in.ExternalJobID == nil || strings.TrimSpace(*in.ExternalJobID) == "" {
+		return false, nil
+	}
+	err := tx.Unscoped().
+		Where("user_id = ? AND source = ? AND external_job_id = ?", userID, in.Source, *in.ExternalJobID).
+		First(m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func keyOf(m *JobApplication) (importKey, bool) {
+	if m.ExternalJobID == nil || strings.TrimSpace(*m.ExternalJobID) == "" {
+		return importKey{}, false
+	}
+	return importKey{source: m.Source, externalJobID: *m.ExternalJobID}, true
+}
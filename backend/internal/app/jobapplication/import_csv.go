@@ -0,0 +1,217 @@
+package jobapplication
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"appliedTo/internal/platform/validate"
+)
+
+var (
+	ErrInvalidCSV     = errors.New("invalid csv")
+	ErrInvalidMapping = errors.New("invalid column mapping")
+)
+
+// CSVMapping maps job application fields, named by their JSON path such as
+// "title" or "employment.salaryRange.from", to CSV column headers. Without a
+// mapping the headers themselves must be field paths; other columns are
+// ignored. Field and header names are matched case-insensitively.
+type CSVMapping map[string]string
+
+// csvField parses a non-empty cell into the field it is mapped to.
+type csvField func(d *JobApplicationCreateDto, v string) error
+
+var csvFields = map[string]csvField{
+	"company":        func(d *JobApplicationCreateDto, v string) error { d.Company = v; return nil },
+	"title":          func(d *JobApplicationCreateDto, v string) error { d.Title = v; return nil },
+	"description":    func(d *JobApplicationCreateDto, v string) error { d.Description = &v; return nil },
+	"status":         func(d *JobApplicationCreateDto, v string) error { d.Status = v; return nil },
+	"source":         func(d *JobApplicationCreateDto, v string) error { d.Source = v; return nil },
+	"appliedAt":      func(d *JobApplicationCreateDto, v string) error { return parseCSVTime(&d.AppliedAt, v) },
+	"nextFollowUpAt": func(d *JobApplicationCreateDto, v string) error { return parseCSVTime(&d.NextFollowUpAt, v) },
+	"lastContactAt":  func(d *JobApplicationCreateDto, v string) error { return parseCSVTime(&d.LastContactAt, v) },
+	"postingUrl":     func(d *JobApplicationCreateDto, v string) error { d.PostingURL = &v; return nil },
+	"companyUrl":     func(d *JobApplicationCreateDto, v string) error { d.CompanyURL = &v; return nil },
+	"contactName":    func(d *JobApplicationCreateDto, v string) error { d.ContactName = &v; return nil },
+	"contactEmail":   func(d *JobApplicationCreateDto, v string) error { d.ContactEmail = &v; return nil },
+	"externalJobId":  func(d *JobApplicationCreateDto, v string) error { d.ExternalJobID = &v; return nil },
+	"location":       func(d *JobApplicationCreateDto, v string) error { d.Location = &v; return nil },
+	"tags": func(d *JobApplicationCreateDto, v string) error {
+		for _, t := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+			if t = strings.TrimSpace(t); t != "" {
+				d.Tags = append(d.Tags, t)
+			}
+		}
+		return nil
+	},
+
+	"employment.type":         func(d *JobApplicationCreateDto, v string) error { d.Employment.Type = v; return nil },
+	"employment.duration":     func(d *JobApplicationCreateDto, v string) error { d.Employment.Duration = &v; return nil },
+	"employment.workLocation": func(d *JobApplicationCreateDto, v string) error { d.Employment.WorkLocation = v; return nil },
+	"employment.seniority":    func(d *JobApplicationCreateDto, v string) error { d.Employment.Seniority = &v; return nil },
+	"employment.hoursPerWeek": func(d *JobApplicationCreateDto, v string) error {
+		var n int
+		if err := parseCSVInt(&n, v); err != nil {
+			return err
+		}
+		d.Employment.HoursPerWeek = &n
+		return nil
+	},
+
+	"employment.salaryRange.from": func(d *JobApplicationCreateDto, v string) error {
+		return parseCSVInt(&csvSalaryRange(d).From, v)
+	},
+	"employment.salaryRange.to": func(d *JobApplicationCreateDto, v string) error {
+		return parseCSVInt(&csvSalaryRange(d).To, v)
+	},
+	"employment.salaryRange.currency": func(d *JobApplicationCreateDto, v string) error {
+		csvSalaryRange(d).Currency = v
+		return nil
+	},
+	"employment.salaryRange.period": func(d *JobApplicationCreateDto, v string) error {
+		csvSalaryRange(d).Period = v
+		return nil
+	},
+	"employment.salaryRange.negotiable": func(d *JobApplicationCreateDto, v string) error {
+		b, err := strconv.ParseBool(strings.ToLower(v))
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		csvSalaryRange(d).Negotiable = b
+		return nil
+	},
+}
+
+// csvFieldNames resolves lower-cased field paths to their canonical name.
+var csvFieldNames = func() map[string]string {
+	m := make(map[string]string, len(csvFields))
+	for name := range csvFields {
+		m[strings.ToLower(name)] = name
+	}
+	return m
+}()
+
+// ParseCSV decodes a CSV file with a header line into import rows. Empty cells
+// leave their field unset. Cells that cannot be parsed fail their row with
+// validate.Errors; a malformed file or mapping fails the whole import.
+func ParseCSV(r io.Reader, mapping CSVMapping) ([]ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmptyImport
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCSV, err)
+	}
+	if len(header) > 0 {
+		// Spreadsheet exports often start with a UTF-8 byte order mark.
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	columns, err := resolveColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCSV, err)
+		}
+		if len(rows) == MaxImportRows {
+			return nil, ErrImportTooLarge
+		}
+		rows = append(rows, decodeCSVRecord(len(rows)+1, record, columns))
+	}
+	return rows, nil
+}
+
+// resolveColumns returns the field each column index is decoded into.
+func resolveColumns(header []string, mapping CSVMapping) (map[int]string, error) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	columns := make(map[int]string)
+	if len(mapping) == 0 {
+		for h, i := range index {
+			if name, ok := csvFieldNames[h]; ok {
+				columns[i] = name
+			}
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("%w: no column header names a field", ErrInvalidMapping)
+		}
+		return columns, nil
+	}
+
+	for field, col := range mapping {
+		name, ok := csvFieldNames[strings.ToLower(strings.TrimSpace(field))]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidMapping, field)
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(col))]
+		if !ok {
+			return nil, fmt.Errorf("%w: no column %q", ErrInvalidMapping, col)
+		}
+		columns[i] = name
+	}
+	return columns, nil
+}
+
+func decodeCSVRecord(row int, record []string, columns map[int]string) ImportRow {
+	out := ImportRow{Row: row}
+	var errs validate.Errors
+	for i, cell := range record {
+		name, ok := columns[i]
+		v := strings.TrimSpace(cell)
+		if !ok || v == "" {
+			continue
+		}
+		if err := csvFields[name](&out.In, v); err != nil {
+			errs.Add(name, err.Error())
+		}
+	}
+	out.Err = errs.Err()
+	return out
+}
+
+func csvSalaryRange(d *JobApplicationCreateDto) *SalaryRangeDto {
+	if d.Employment.SalaryRange == nil {
+		d.Employment.SalaryRange = &SalaryRangeDto{}
+	}
+	return d.Employment.SalaryRange
+}
+
+func parseCSVInt(dst *int, v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return errors.New("must be a whole number")
+	}
+	*dst = n
+	return nil
+}
+
+// parseCSVTime accepts RFC 3339 timestamps and plain dates (midnight UTC).
+func parseCSVTime(dst **time.Time, v string) error {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, v); err == nil {
+			*dst = &t
+			return nil
+		}
+	}
+	return errors.New("must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+}
@@ -0,0 +1,140 @@
+package jobapplication
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Interviews belong to a job application and are only reachable through an
+// application the user owns; an interview of another application is
+// indistinguishable from a missing one.
+
+// ownedApplication loads the id and status of the user's application id.
+func ownedApplication(tx *gorm.DB, userID, id uint) (JobApplication, error) {
+	var m JobApplication
+	err := tx.Where("user_id = ?", userID).Select("id", "status").First(&m, id).Error
+	return m, err
+}
+
+func (s *Service) interviewOf(ctx context.Context, userID, appID, id uint) (Interview, error) {
+	var iv Interview
+	err := s.db.WithContext(ctx).
+		Joins("JOIN job_applications ja ON ja.id = interviews.job_application_id AND ja.deleted_at IS NULL").
+		Where("ja.user_id = ? AND interviews.job_application_id = ?", userID, appID).
+		First(&iv, "interviews.id = ?", id).Error
+	return iv, err
+}
+
+// LIST
+func (s *Service) ListInterviews(ctx context.Context, userID, appID uint) ([]InterviewPublicDto, error) {
+	if _, err := ownedApplication(s.db.WithContext(ctx), userID, appID); err != nil {
+		return nil, err
+	}
+
+	var ivs []Interview
+	if err := s.db.WithContext(ctx).
+		Where("job_application_id = ?", appID).
+		Order("scheduled_at, id").
+		Find(&ivs).Error; err != nil {
+		return nil, err
+	}
+
+	out := make([]InterviewPublicDto, 0, len(ivs))
+	for _, iv := range ivs {
+		out = append(out, MapInterviewToPublicDto(iv))
+	}
+	return out, nil
+}
+
+// CREATE
+// CreateInterview schedules a round. With in.AdvanceStatus an application
+// that has not reached StatusInterview yet is moved there through patchInTx,
+// in the same transaction, and the updated application is returned.
+func (s *Service) CreateInterview(ctx context.Context, userID, appID uint, in InterviewCreateDto) (InterviewPublicDto, JobApplicationPublicDto, error) {
+	var iv Interview
+	OverwriteInterviewModel(&iv, in.BaseInterviewDto)
+	iv.JobApplicationID = appID
+	if err := validateInterview(&iv); err != nil {
+		return InterviewPublicDto{}, JobApplicationPublicDto{}, err
+	}
+
+	var app JobApplication
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		if app, err = ownedApplication(tx, userID, appID); err != nil {
+			return err
+		}
+		if err := tx.Create(&iv).Error; err != nil {
+			return err
+		}
+		if in.AdvanceStatus {
+			for _, next := range pathTo(app.Status, StatusInterview) {
+				status := string(next)
+				if app, err = patchInTx(tx, userID, appID, JobApplicationPatchDto{Status: &status}); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Where("user_id = ?", userID).First(&app, appID).Error
+	})
+	if err != nil {
+		return InterviewPublicDto{}, JobApplicationPublicDto{}, err
+	}
+	out, err := s.toPublic(ctx, app)
+	if err != nil {
+		return InterviewPublicDto{}, JobApplicationPublicDto{}, err
+	}
+	return MapInterviewToPublicDto(iv), out, nil
+}
+
+// READ
+func (s *Service) GetInterview(ctx context.Context, userID, appID, id uint) (InterviewPublicDto, error) {
+	iv, err := s.interviewOf(ctx, userID, appID, id)
+	if err != nil {
+		return InterviewPublicDto{}, err
+	}
+	return MapInterviewToPublicDto(iv), nil
+}
+
+// UPDATE (full replace)
+func (s *Service) UpdateInterview(ctx context.Context, userID, appID, id uint, in BaseInterviewDto) (InterviewPublicDto, error) {
+	iv, err := s.interviewOf(ctx, userID, appID, id)
+	if err != nil {
+		return InterviewPublicDto{}, err
+	}
+
+	OverwriteInterviewModel(&iv, in)
+	if err := validateInterview(&iv); err != nil {
+		return InterviewPublicDto{}, err
+	}
+	if err := s.db.WithContext(ctx).Save(&iv).Error; err != nil {
+		return InterviewPublicDto{}, err
+	}
+	return MapInterviewToPublicDto(iv), nil
+}
+
+// PATCH (partial update)
+func (s *Service) PatchInterview(ctx context.Context, userID, appID, id uint, patch InterviewPatchDto) (InterviewPublicDto, error) {
+	iv, err := s.interviewOf(ctx, userID, appID, id)
+	if err != nil {
+		return InterviewPublicDto{}, err
+	}
+
+	PatchInterviewModel(&iv, patch)
+	if err := validateInterview(&iv); err != nil {
+		return InterviewPublicDto{}, err
+	}
+	if err := s.db.WithContext(ctx).Save(&iv).Error; err != nil {
+		return InterviewPublicDto{}, err
+	}
+	return MapInterviewToPublicDto(iv), nil
+}
+
+// DELETE
+func (s *Service) DeleteInterview(ctx context.Context, userID, appID, id uint) error {
+	iv, err := s.interviewOf(ctx, userID, appID, id)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Delete(&iv).Error
+}
@@ -0,0 +1,384 @@
+package jobapplication
+
+import (
+	"appliedTo/internal/app/salary"
+	"appliedTo/internal/utils"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort field")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+const (
+	defaultListLimit  = 20
+	maxListLimit      = 100
+	defaultSort       = "-id"
+	defaultSearchSort = "-relevance"
+)
+
+// ---- full-text search ----
+
+// The search_vector column is maintained by platform/db.Migrate with the
+// 'english' text search configuration; queries must use the same one. The
+// snippet is HTML: the text is escaped before ts_headline wraps matches in
+// <mark></mark>, and the text search parser keeps the entities whole.
+const (
+	searchQuery   = "websearch_to_tsquery('english', ?)"
+	searchRank    = "ts_rank(search_vector, " + searchQuery + ")"
+	searchText    = "concat_ws(' · ', company, title, contact_name, location, description)"
+	searchHTML    = "replace(replace(replace(" + searchText + ", '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+	searchSnippet = "ts_headline('english', " + searchHTML + ", " +
+		searchQuery + ", 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')"
+)
+
+// listRow is a job application together with its search rank and snippet,
+// which are only populated for full-text searches, and its normalized salary
+// when sorting by salary.
+type listRow struct {
+	JobApplication
+	SearchRank    *float32
+	SearchSnippet *string
+	SalarySort    *float64
+}
+
+// ---- sorting ----
+
+type sortKind int
+
+const (
+	sortKindID sortKind = iota
+	sortKindString
+	sortKindTime
+	sortKindRank
+	sortKindSalary
+)
+
+// sortColumn describes a column the listing can be ordered by. value extracts
+// the column's value from a row for the next cursor; nil stands for NULL.
+// Salary columns have no fixed expr; it depends on the requested currency.
+type sortColumn struct {
+	expr     string
+	kind     sortKind
+	nullable bool
+	salary   *salary.Columns
+	value    func(r *listRow) any
+}
+
+var sortColumns = map[string]sortColumn{
+	"id": {expr: "id", kind: sortKindID,
+		value: func(r *listRow) any { return r.ID }},
+	"status": {expr: "status", kind: sortKindString,
+		value: func(r *listRow) any { return string(r.Status) }},
+	"source": {expr: "source", kind: sortKindString,
+		value: func(r *listRow) any { return string(r.Source) }},
+	"employmentType": {expr: "employment_type", kind: sortKindString,
+		value: func(r *listRow) any { return string(r.Employment.Type) }},
+	"workLocation": {expr: "employment_work_location", kind: sortKindString,
+		value: func(r *listRow) any { return string(r.Employment.WorkLocation) }},
+	"appliedAt": {expr: "applied_at", kind: sortKindTime, nullable: true,
+		value: func(r *listRow) any { return timeOrNil(r.AppliedAt) }},
+	"nextFollowUpAt": {expr: "next_follow_up_at", kind: sortKindTime, nullable: true,
+		value: func(r *listRow) any { return timeOrNil(r.NextFollowUpAt) }},
+	"lastContactAt": {expr: "last_contact_at", kind: sortKindTime, nullable: true,
+		value: func(r *listRow) any { return timeOrNil(r.LastContactAt) }},
+	"salaryFrom": {kind: sortKindSalary, nullable: true, salary: &salaryFromColumns,
+		value: func(r *listRow) any { return floatOrNil(r.SalarySort) }},
+	"salaryTo": {kind: sortKindSalary, nullable: true, salary: &salaryToColumns,
+		value: func(r *listRow) any { return floatOrNil(r.SalarySort) }},
+	"relevance": {expr: searchRank, kind: sortKindRank,
+		value: func(r *listRow) any {
+			if r.SearchRank == nil {
+				return float32(0)
+			}
+			return *r.SearchRank
+		}},
+}
+
+type sortSpec struct {
+	name string
+	col  sortColumn
+	desc bool
+	args []any // bound to the placeholders of col.expr
+}
+
+func parseSort(raw, search string, n salary.Normalizer) (sortSpec, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		raw = defaultSort
+		if search != "" {
+			raw = defaultSearchSort
+		}
+	}
+	desc := strings.HasPrefix(raw, "-")
+	name := strings.TrimPrefix(raw, "-")
+	col, ok := sortColumns[name]
+	if !ok {
+		return sortSpec{}, ErrInvalidSort
+	}
+	spec := sortSpec{name: name, col: col, desc: desc}
+	if col.kind == sortKindRank {
+		if search == "" {
+			return sortSpec{}, ErrInvalidSort
+		}
+		spec.args = []any{search}
+	}
+	if col.salary != nil {
+		// Cursors are only valid for the currency they were issued for.
+		spec.name += "@" + n.Currency()
+		spec.col.expr, spec.args = n.AnnualSQL(*col.salary)
+	}
+	return spec, nil
+}
+
+func (s sortSpec) orderBy() clause.OrderBy {
+	dir := "ASC"
+	if s.desc {
+		dir = "DESC"
+	}
+	if s.col.kind == sortKindID {
+		return clause.OrderBy{Expression: clause.Expr{SQL: "id " + dir}}
+	}
+	// NULLs always sort last so the keyset condition below stays simple.
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                fmt.Sprintf("%s %s NULLS LAST, id %s", s.col.expr, dir, dir),
+		Vars:               s.args,
+		WithoutParentheses: true,
+	}}
+}
+
+// after restricts q to the rows that follow the cursor in this sort order.
+func (s sortSpec) after(q *gorm.DB, cur cursor, value any) *gorm.DB {
+	op := ">"
+	if s.desc {
+		op = "<"
+	}
+	if s.col.kind == sortKindID {
+		return q.Where("id "+op+" ?", cur.ID)
+	}
+	e := s.col.expr
+	if value == nil {
+		// Past the last non-NULL value: only the NULL tail is left.
+		return q.Where(e+" IS NULL AND id "+op+" ?", append(s.args, cur.ID)...)
+	}
+	cond := fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?)", e, op, e, op)
+	vars := append(append(append([]any{}, s.args...), value), s.args...)
+	vars = append(vars, value, cur.ID)
+	if s.col.nullable {
+		cond += " OR " + e + " IS NULL"
+		vars = append(vars, s.args...)
+	}
+	return q.Where(cond+")", vars...)
+}
+
+// ---- cursor ----
+
+// cursor is the opaque pagination token: the sort it was issued for and the
+// sort value and ID of the last row of the previous page.
+type cursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v,omitempty"`
+	ID    uint            `json:"id"`
+}
+
+func encodeCursor(s sortSpec, r *listRow) (string, error) {
+	cur := cursor{Sort: s.name, ID: r.ID}
+	if s.col.kind != sortKindID {
+		if v := s.col.value(r); v != nil {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			cur.Value = b
+		}
+	}
+	b, err := json.Marshal(cur)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor parses a token issued for sort s and returns it together with
+// its typed sort value (nil for NULL).
+func decodeCursor(raw string, s sortSpec) (cursor, any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor{}, nil, ErrInvalidCursor
+	}
+	var cur cursor
+	if err := json.Unmarshal(b, &cur); err != nil || cur.ID == 0 || cur.Sort != s.name {
+		return cursor{}, nil, ErrInvalidCursor
+	}
+	if s.col.kind == sortKindID {
+		return cur, nil, nil
+	}
+	if len(cur.Value) == 0 {
+		if !s.col.nullable {
+			return cursor{}, nil, ErrInvalidCursor
+		}
+		return cur, nil, nil
+	}
+
+	var value any
+	switch s.col.kind {
+	case sortKindTime:
+		var t time.Time
+		err, value = json.Unmarshal(cur.Value, &t), t
+	case sortKindRank:
+		var f float32
+		err, value = json.Unmarshal(cur.Value, &f), f
+	case sortKindSalary:
+		var f float64
+		err, value = json.Unmarshal(cur.Value, &f), f
+	default:
+		var v string
+		err, value = json.Unmarshal(cur.Value, &v), v
+	}
+	if err != nil {
+		return cursor{}, nil, ErrInvalidCursor
+	}
+	return cur, value, nil
+}
+
+// ---- filtering ----
+
+// applyFilter narrows q to the applications matching f. Salaries are
+// compared as normalized by n.
+func applyFilter(q *gorm.DB, f JobApplicationFilter, n salary.Normalizer) *gorm.DB {
+	if len(f.CompanyID) > 0 {
+		q = q.Where("company_id IN ?", f.CompanyID)
+	}
+	if len(f.Status) > 0 {
+		q = q.Where("status IN ?", f.Status)
+	}
+	if len(f.Source) > 0 {
+		q = q.Where("source IN ?", f.Source)
+	}
+	if len(f.EmploymentType) > 0 {
+		q = q.Where("employment_type IN ?", f.EmploymentType)
+	}
+	if len(f.WorkLocation) > 0 {
+		q = q.Where("employment_work_location IN ?", f.WorkLocation)
+	}
+	if len(f.Tags) > 0 {
+		q = q.Where("tags @> ?", utils.ToJSONTags(f.Tags))
+	}
+
+	q = whereBetween(q, "applied_at", f.AppliedFrom, f.AppliedTo)
+	q = whereBetween(q, "next_follow_up_at", f.NextFollowUpFrom, f.NextFollowUpTo)
+	q = whereBetween(q, "last_contact_at", f.LastContactFrom, f.LastContactTo)
+
+	if f.SalaryMin != nil {
+		expr, args := n.AnnualSQL(salaryToColumns)
+		q = q.Where(expr+" >= ?", append(args, *f.SalaryMin)...)
+	}
+	if f.SalaryMax != nil {
+		expr, args := n.AnnualSQL(salaryFromColumns)
+		q = q.Where(expr+" <= ?", append(args, *f.SalaryMax)...)
+	}
+	return q
+}
+
+func whereBetween(q *gorm.DB, column string, from, to *time.Time) *gorm.DB {
+	if from != nil {
+		q = q.Where(column+" >= ?", *from)
+	}
+	if to != nil {
+		q = q.Where(column+" <= ?", *to)
+	}
+	return q
+}
+
+func floatOrNil(f *float64) any {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+func timeOrNil(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// ---- service ----
+
+// LIST
+func (s *Service) List(ctx context.Context, userID uint, in JobApplicationListQuery) (JobApplicationListDto, error) {
+	search := strings.TrimSpace(in.Q)
+	n, err := s.salaries.Normalizer(ctx, in.SalaryCurrency)
+	if err != nil {
+		return JobApplicationListDto{}, err
+	}
+	sort, err := parseSort(in.Sort, search, n)
+	if err != nil {
+		return JobApplicationListDto{}, err
+	}
+
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	q := applyFilter(s.owned(ctx, userID).Model(&JobApplication{}), in.JobApplicationFilter, n)
+	columns, args := "job_applications.*", []any{}
+	if search != "" {
+		columns += ", " + searchRank + " AS search_rank, " + searchSnippet + " AS search_snippet"
+		args = append(args, search, search)
+		q = q.Where("search_vector @@ "+searchQuery, search)
+	}
+	if sort.col.kind == sortKindSalary {
+		columns += ", " + sort.col.expr + " AS salary_sort"
+		args = append(args, sort.args...)
+	}
+	q = q.Select(columns, args...)
+	if in.Cursor != "" {
+		cur, value, err := decodeCursor(in.Cursor, sort)
+		if err != nil {
+			return JobApplicationListDto{}, err
+		}
+		q = sort.after(q, cur, value)
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	var rows []listRow
+	if err := q.Order(sort.orderBy()).Limit(limit + 1).Find(&rows).Error; err != nil {
+		return JobApplicationListDto{}, err
+	}
+
+	out := JobApplicationListDto{Items: make([]JobApplicationListItemDto, 0, min(len(rows), limit))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		next, err := encodeCursor(sort, &rows[len(rows)-1])
+		if err != nil {
+			return JobApplicationListDto{}, err
+		}
+		out.NextCursor = &next
+	}
+	for _, r := range rows {
+		dto := MapModelToPublicDto(r.JobApplication)
+		dto.SalaryNormalized = normalizedSalary(&r.JobApplication, n)
+		out.Items = append(out.Items, JobApplicationListItemDto{
+			JobApplicationPublicDto: dto,
+			Rank:                    r.SearchRank,
+			Snippet:                 r.SearchSnippet,
+		})
+	}
+	return out, nil
+}
@@ -4,6 +4,7 @@ import (
 	"appliedTo/internal/platform/patch"
 	"appliedTo/internal/utils"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -16,7 +17,7 @@ func toModelSalaryRange(in *SalaryRangeDto) *SalaryRange {
 	return &SalaryRange{
 		From:       in.From,
 		To:         in.To,
-		Currency:   in.Currency,
+		Currency:   normalizeCurrency(in.Currency),
 		Period:     SalaryPeriod(in.Period),
 		Negotiable: in.Negotiable,
 	}
@@ -35,6 +36,10 @@ func toDtoSalaryRange(in *SalaryRange) *SalaryRangeDto {
 	}
 }
 
+func normalizeCurrency(c string) string {
+	return strings.ToUpper(strings.TrimSpace(c))
+}
+
 // ---- helpers: Employment ----
 
 func mapEmploymentDtoToModel(e EmploymentDto) Employment {
@@ -83,7 +88,9 @@ func patchEmploymentModel(e *Employment, dto EmploymentPatchDto) {
 func patchSalaryRange(sr *SalaryRange, dto SalaryRangePatchDto) {
 	patch.Patch(&sr.From, dto.From)
 	patch.Patch(&sr.To, dto.To)
-	patch.Patch(&sr.Currency, dto.Currency)
+	if dto.Currency != nil {
+		sr.Currency = normalizeCurrency(*dto.Currency)
+	}
 	if dto.Period != nil {
 		sr.Period = SalaryPeriod(*dto.Period)
 	}
@@ -162,6 +169,7 @@ func MapModelToPublicDto(m JobApplication) JobApplicationPublicDto {
 		Created: m.CreatedAt.UTC().Format(time.RFC3339),
 		BaseJobApplicationDto: BaseJobApplicationDto{
 			Company:        m.Company,
+			CompanyID:      m.CompanyID,
 			Title:          m.Title,
 			Description:    m.Description,
 			Status:         string(m.Status),
@@ -180,3 +188,102 @@ func MapModelToPublicDto(m JobApplication) JobApplicationPublicDto {
 		},
 	}
 }
+
+func MapStatusChangeToPublicDto(m StatusChange) StatusChangePublicDto {
+	return StatusChangePublicDto{
+		From:      string(m.FromStatus),
+		To:        string(m.ToStatus),
+		ChangedBy: m.ChangedByUserID,
+		ChangedAt: m.ChangedAt.UTC(),
+	}
+}
+
+// --- INTERVIEW MAPPERS ---
+
+const defaultInterviewMinutes = 60
+
+func OverwriteInterviewModel(m *Interview, dto BaseInterviewDto) {
+	m.Type            = InterviewType(dto.Type)
+	m.ScheduledAt     = dto.ScheduledAt
+	m.DurationMinutes = dto.DurationMinutes
+	m.Interviewers    = utils.ToJSONTags(dto.Interviewers)
+	m.Location        = dto.Location
+	m.MeetingURL      = dto.MeetingURL
+	m.Outcome         = InterviewOutcome(dto.Outcome)
+	m.Notes           = dto.Notes
+
+	if m.DurationMinutes == 0 {
+		m.DurationMinutes = defaultInterviewMinutes
+	}
+	if m.Outcome == "" {
+		m.Outcome = OutcomePending
+	}
+}
+
+func PatchInterviewModel(m *Interview, dto InterviewPatchDto) {
+	if dto.Type != nil {
+		m.Type = InterviewType(*dto.Type)
+	}
+	patch.Patch(&m.ScheduledAt, dto.ScheduledAt)
+	patch.Patch(&m.DurationMinutes, dto.DurationMinutes)
+	if dto.Interviewers != nil {
+		m.Interviewers = utils.ToJSONTags(*dto.Interviewers)
+	}
+	patch.PatchRef(&m.Location, dto.Location)
+	patch.PatchRef(&m.MeetingURL, dto.MeetingURL)
+	if dto.Outcome != nil {
+		m.Outcome = InterviewOutcome(*dto.Outcome)
+	}
+	patch.PatchRef(&m.Notes, dto.Notes)
+}
+
+func MapInterviewToPublicDto(m Interview) InterviewPublicDto {
+	interviewers := []string{}
+	if v, err := utils.FromJSONTags(m.Interviewers); err == nil {
+		interviewers = v
+	} else { log.Printf("invalid interviewers JSON for interview id=%d: %v", m.ID, err) }
+	return InterviewPublicDto{
+		ID:        m.ID,
+		CreatedAt: m.CreatedAt.UTC(),
+		UpdatedAt: m.UpdatedAt.UTC(),
+		BaseInterviewDto: BaseInterviewDto{
+			Type:            string(m.Type),
+			ScheduledAt:     m.ScheduledAt,
+			DurationMinutes: m.DurationMinutes,
+			Interviewers:    interviewers,
+			Location:        m.Location,
+			MeetingURL:      m.MeetingURL,
+			Outcome:         string(m.Outcome),
+			Notes:           m.Notes,
+		},
+	}
+}
+
+// --- ACTIVITY MAPPERS ---
+
+func MapActivityToPublicDto(m Activity) ActivityPublicDto {
+	return ActivityPublicDto{
+		ID:         m.ID,
+		Type:       string(m.Type),
+		OccurredAt: m.OccurredAt.UTC(),
+		Body:       m.Body,
+		CreatedBy:  m.CreatedByUserID,
+		CreatedAt:  m.CreatedAt.UTC(),
+	}
+}
+
+// --- DUPLICATE MAPPERS ---
+
+func MapDuplicateCandidateToDto(m JobApplication) DuplicateCandidateDto {
+	return DuplicateCandidateDto{
+		ID:         m.ID,
+		Created:    m.CreatedAt.UTC().Format(time.RFC3339),
+		Company:    m.Company,
+		CompanyID:  m.CompanyID,
+		Title:      m.Title,
+		Status:     string(m.Status),
+		Location:   m.Location,
+		PostingURL: m.PostingURL,
+		AppliedAt:  m.AppliedAt,
+	}
+}
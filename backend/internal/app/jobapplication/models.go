@@ -11,6 +11,7 @@ type JobApplication struct {
 	gorm.Model
 	UserID          uint              `json:"-" gorm:"index;uniqueIndex:uniq_user_extid_src"`
 	Company         string            `json:"company"`
+	CompanyID       *uint             `json:"companyId,omitempty" gorm:"index"`
 	Title           string            `json:"title"`
 	Description     *string           `json:"description,omitempty"`
 	Status          ApplicationStatus `json:"status" gorm:"type:VARCHAR(24);index"`
@@ -28,6 +29,44 @@ type JobApplication struct {
 	Tags            datatypes.JSON    `json:"tags,omitempty" gorm:"type:jsonb;default:'[]'"`
 }
 
+// StatusChange records one transition of a job application's status.
+// FromStatus is empty for the initial status set on creation.
+type StatusChange struct {
+	ID               uint              `json:"id" gorm:"primaryKey"`
+	JobApplicationID uint              `json:"-" gorm:"index;not null"`
+	FromStatus       ApplicationStatus `json:"from" gorm:"type:VARCHAR(24)"`
+	ToStatus         ApplicationStatus `json:"to" gorm:"type:VARCHAR(24);not null"`
+	ChangedByUserID  uint              `json:"changedBy" gorm:"index"`
+	ChangedAt        time.Time         `json:"changedAt" gorm:"autoCreateTime;index"`
+}
+
+// Interview is one interview round of a job application.
+type Interview struct {
+	ID               uint             `json:"id" gorm:"primaryKey"`
+	JobApplicationID uint             `json:"-" gorm:"index;not null"`
+	Type             InterviewType    `json:"type" gorm:"type:VARCHAR(16);not null"`
+	ScheduledAt      time.Time        `json:"scheduledAt" gorm:"not null"`
+	DurationMinutes  int              `json:"durationMinutes"`
+	Interviewers     datatypes.JSON   `json:"interviewers" gorm:"type:jsonb;default:'[]'"`
+	Location         *string          `json:"location,omitempty"`
+	MeetingURL       *string          `json:"meetingUrl,omitempty"`
+	Outcome          InterviewOutcome `json:"outcome" gorm:"type:VARCHAR(16);not null"`
+	Notes            *string          `json:"notes,omitempty"`
+	CreatedAt        time.Time        `json:"createdAt"`
+	UpdatedAt        time.Time        `json:"updatedAt"`
+}
+
+// Activity is an entry in the append-only timeline of a job application.
+type Activity struct {
+	ID               uint         `json:"id" gorm:"primaryKey"`
+	JobApplicationID uint         `json:"-" gorm:"not null"`
+	Type             ActivityType `json:"type" gorm:"type:VARCHAR(24);not null"`
+	OccurredAt       time.Time    `json:"occurredAt" gorm:"not null"`
+	Body             *string      `json:"body,omitempty"`
+	CreatedByUserID  uint         `json:"createdBy" gorm:"not null"`
+	CreatedAt        time.Time    `json:"createdAt"`
+}
+
 type Employment struct {
 	Type          EmploymentType `json:"type"`
 	Duration      *string        `json:"duration,omitempty"`
@@ -52,6 +91,8 @@ const (
 	Contract EmploymentType = "Contract"
 )
 
+var EmploymentTypes = []EmploymentType{FullTime, PartTime, Contract}
+
 type WorkLocation string
 const (
 	Onsite WorkLocation = "Onsite"
@@ -59,6 +100,8 @@ const (
 	Remote WorkLocation = "Remote"
 )
 
+var WorkLocations = []WorkLocation{Onsite, Hybrid, Remote}
+
 type ApplicationStatus string
 const (
 	StatusApplied    ApplicationStatus = "Applied"
@@ -70,6 +113,11 @@ const (
 	StatusWithdrawn  ApplicationStatus = "Withdrawn"
 )
 
+var ApplicationStatuses = []ApplicationStatus{
+	StatusApplied, StatusScreening, StatusInterview, StatusOffer,
+	StatusRejected, StatusHired, StatusWithdrawn,
+}
+
 type ApplicationSource string
 const (
 	SourceReferral    ApplicationSource = "Referral"
@@ -81,6 +129,11 @@ const (
 	SourceOther       ApplicationSource = "Other"
 )
 
+var ApplicationSources = []ApplicationSource{
+	SourceReferral, SourceCompanySite, SourceJobBoard, SourceLinkedIn,
+	SourceIndeed, SourceAgency, SourceOther,
+}
+
 type SalaryPeriod string
 const (
 	PerYear  SalaryPeriod = "Year"
@@ -89,3 +142,50 @@ const (
 	PerDay   SalaryPeriod = "Day"
 	PerHour  SalaryPeriod = "Hour"
 )
+
+var SalaryPeriods = []SalaryPeriod{PerYear, PerMonth, PerWeek, PerDay, PerHour}
+
+type InterviewType string
+const (
+	InterviewPhone     InterviewType = "Phone"
+	InterviewTechnical InterviewType = "Technical"
+	InterviewOnsite    InterviewType = "Onsite"
+	InterviewPanel     InterviewType = "Panel"
+)
+
+var InterviewTypes = []InterviewType{InterviewPhone, InterviewTechnical, InterviewOnsite, InterviewPanel}
+
+type InterviewOutcome string
+const (
+	OutcomePending   InterviewOutcome = "Pending"
+	OutcomePassed    InterviewOutcome = "Passed"
+	OutcomeFailed    InterviewOutcome = "Failed"
+	OutcomeCancelled InterviewOutcome = "Cancelled"
+)
+
+var InterviewOutcomes = []InterviewOutcome{OutcomePending, OutcomePassed, OutcomeFailed, OutcomeCancelled}
+
+type ActivityType string
+const (
+	ActivityNote          ActivityType = "Note"
+	ActivityEmailSent     ActivityType = "EmailSent"
+	ActivityEmailReceived ActivityType = "EmailReceived"
+	ActivityCall          ActivityType = "Call"
+	ActivityInterview     ActivityType = "Interview"
+	ActivityOfferReceived ActivityType = "OfferReceived"
+)
+
+var ActivityTypes = []ActivityType{
+	ActivityNote, ActivityEmailSent, ActivityEmailReceived,
+	ActivityCall, ActivityInterview, ActivityOfferReceived,
+}
+
+// IsContact reports whether the activity is an exchange with the company,
+// which moves the application's LastContactAt.
+func (t ActivityType) IsContact() bool {
+	switch t {
+	case ActivityEmailSent, ActivityEmailReceived, ActivityCall, ActivityInterview, ActivityOfferReceived:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package jobapplication
+
+import (
+	"context"
+
+	"appliedTo/internal/app/salary"
+)
+
+// Salary ranges normalized to yearly amounts, for filtering and sorting.
+// The upper bound of a range without one is its lower bound.
+var (
+	salaryFromColumns = salary.Columns{
+		Amount:       "employment_salary_range_from",
+		Currency:     "employment_salary_range_currency",
+		Period:       "employment_salary_range_period",
+		HoursPerWeek: "employment_hours_per_week",
+	}
+	salaryToColumns = salary.Columns{
+		Amount:       "GREATEST(employment_salary_range_from, employment_salary_range_to)",
+		Currency:     salaryFromColumns.Currency,
+		Period:       salaryFromColumns.Period,
+		HoursPerWeek: salaryFromColumns.HoursPerWeek,
+	}
+)
+
+// normalizedSalary returns the salary range of m as a yearly amount, or nil
+// if it cannot be normalized.
+func normalizedSalary(m *JobApplication, n salary.Normalizer) *salary.AnnualDto {
+	sr := m.Employment.SalaryRange
+	if sr == nil {
+		return nil
+	}
+	a, ok := n.Annual(salary.Range{
+		From:         sr.From,
+		To:           sr.To,
+		Currency:     sr.Currency,
+		Period:       string(sr.Period),
+		HoursPerWeek: m.Employment.HoursPerWeek,
+	})
+	if !ok {
+		return nil
+	}
+	return &a
+}
+
+// toPublic maps m with its salary normalized to the base currency.
+func (s *Service) toPublic(ctx context.Context, m JobApplication) (JobApplicationPublicDto, error) {
+	n, err := s.salaries.Normalizer(ctx, "")
+	if err != nil {
+		return JobApplicationPublicDto{}, err
+	}
+	out := MapModelToPublicDto(m)
+	out.SalaryNormalized = normalizedSalary(&m, n)
+	return out, nil
+}
@@ -1,87 +1,127 @@
 package jobapplication
 
 import (
-	"appliedTo/internal/platform/validate"
 	"context"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"appliedTo/internal/app/salary"
 )
 
 type Service struct {
-	db *gorm.DB
+	db       *gorm.DB
+	salaries *salary.Service
+}
+
+func NewService(db *gorm.DB, salaries *salary.Service) *Service {
+	return &Service{db: db, salaries: salaries}
 }
 
-func NewService(db *gorm.DB) *Service {
-	return &Service{db: db}
+// owned scopes a query to the applications of the given user. Records of
+// other users are indistinguishable from missing ones (gorm.ErrRecordNotFound).
+func (s *Service) owned(ctx context.Context, userID uint) *gorm.DB {
+	return s.db.WithContext(ctx).Where("user_id = ?", userID)
 }
 
 // CREATE
-func (s *Service) Create(ctx context.Context, in JobApplicationCreateDto) (JobApplicationPublicDto, error) {
-	if err := validate.Required(
-		validate.Field{Name: "title",           Value: in.BaseJobApplicationDto.Title},
-		validate.Field{Name: "employment type", Value: in.BaseJobApplicationDto.Employment.Type},
-		validate.Field{Name: "work location",   Value: in.BaseJobApplicationDto.Employment.WorkLocation},
-	); err != nil {
+// Create rejects an application that looks like one the user already has
+// with a *DuplicateError, unless force is set.
+func (s *Service) Create(ctx context.Context, userID uint, in JobApplicationCreateDto, force bool) (JobApplicationPublicDto, error) {
+	m := CreateModel(in)
+	m.UserID = userID
+	if m.Status == "" {
+		m.Status = StatusApplied
+	}
+	if err := validateModel(&m); err != nil {
 		return JobApplicationPublicDto{}, err
 	}
 
-	m := CreateModel(in)
-	if err := s.db.WithContext(ctx).Create(&m).Error; err != nil {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := linkCompany(tx, userID, &m, in.CompanyID); err != nil {
+			return err
+		}
+		if !force {
+			dups, err := findDuplicates(tx, userID, &m)
+			if err != nil {
+				return err
+			}
+			if len(dups) > 0 {
+				return &DuplicateError{Candidates: dups}
+			}
+		}
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+		if err := linkContact(tx, userID, &m); err != nil {
+			return err
+		}
+		return recordStatusChange(tx, userID, &m, "")
+	})
+	if err != nil {
 		return JobApplicationPublicDto{}, err
 	}
-	return MapModelToPublicDto(m), nil
+	return s.toPublic(ctx, m)
 }
 
 // READ
-func (s *Service) GetByID(ctx context.Context, id uint) (JobApplicationPublicDto, error) {
+func (s *Service) GetByID(ctx context.Context, userID, id uint) (JobApplicationPublicDto, error) {
 	var m JobApplication
-	if err := s.db.WithContext(ctx).First(&m, id).Error; err != nil {
+	if err := s.owned(ctx, userID).First(&m, id).Error; err != nil {
 		return JobApplicationPublicDto{}, err
 	}
-	return MapModelToPublicDto(m), nil
+	return s.toPublic(ctx, m)
 }
 
 // UPDATE (full replace)
-func (s *Service) Update(ctx context.Context, id uint, in JobApplicationCreateDto) (JobApplicationPublicDto, error) {
+// Update locks the row for the transaction, so that concurrent updates check
+// their status transition against the status they actually replace.
+func (s *Service) Update(ctx context.Context, userID, id uint, in JobApplicationCreateDto) (JobApplicationPublicDto, error) {
 	var m JobApplication
-	if err := s.db.WithContext(ctx).First(&m, id).Error; err != nil {
-		return JobApplicationPublicDto{}, err
-	}
-
-	if err := validate.Required(
-		validate.Field{Name: "title",           Value: in.Title},
-		validate.Field{Name: "employment type", Value: in.Employment.Type},
-		validate.Field{Name: "work location",   Value: in.Employment.WorkLocation},
-	); err != nil {
-		return JobApplicationPublicDto{}, err
-	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOwned(tx, userID, id, &m); err != nil {
+			return err
+		}
 
-	OverwriteModel(&m, in)
+		from := m.Status
+		OverwriteModel(&m, in)
+		if err := validateModel(&m); err != nil {
+			return err
+		}
+		if err := checkTransition(from, m.Status); err != nil {
+			return err
+		}
 
-	if err := s.db.WithContext(ctx).Save(&m).Error; err != nil {
+		if err := linkCompany(tx, userID, &m, in.CompanyID); err != nil {
+			return err
+		}
+		if err := saveInTx(tx, userID, &m, from); err != nil {
+			return err
+		}
+		return linkContact(tx, userID, &m)
+	})
+	if err != nil {
 		return JobApplicationPublicDto{}, err
 	}
-	return MapModelToPublicDto(m), nil
+	return s.toPublic(ctx, m)
 }
 
 // PATCH (partial update)
-func (s *Service) Patch(ctx context.Context, id uint, patch JobApplicationPatchDto) (JobApplicationPublicDto, error) {
+func (s *Service) Patch(ctx context.Context, userID, id uint, patch JobApplicationPatchDto) (JobApplicationPublicDto, error) {
 	var m JobApplication
-	if err := s.db.WithContext(ctx).First(&m, id).Error; err != nil {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) (err error) {
+		m, err = patchInTx(tx, userID, id, patch)
+		return err
+	})
+	if err != nil {
 		return JobApplicationPublicDto{}, err
 	}
-
-	PatchModel(&m, patch)
-
-	if err := s.db.WithContext(ctx).Save(&m).Error; err != nil {
-		return JobApplicationPublicDto{}, err
-	}
-	return MapModelToPublicDto(m), nil
+	return s.toPublic(ctx, m)
 }
 
 // DELETE
-func (s *Service) Delete(ctx context.Context, id uint) error {
-	tx := s.db.WithContext(ctx).Delete(&JobApplication{}, id)
+func (s *Service) Delete(ctx context.Context, userID, id uint) error {
+	tx := s.owned(ctx, userID).Delete(&JobApplication{}, id)
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -90,3 +130,88 @@ func (s *Service) Delete(ctx context.Context, id uint) error {
 	}
 	return nil
 }
+
+// HISTORY
+func (s *Service) History(ctx context.Context, userID, id uint) ([]StatusChangePublicDto, error) {
+	var m JobApplication
+	if err := s.owned(ctx, userID).Select("id").First(&m, id).Error; err != nil {
+		return nil, err
+	}
+
+	var changes []StatusChange
+	if err := s.db.WithContext(ctx).
+		Where("job_application_id = ?", m.ID).
+		Order("changed_at, id").
+		Find(&changes).Error; err != nil {
+		return nil, err
+	}
+
+	out := make([]StatusChangePublicDto, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, MapStatusChangeToPublicDto(c))
+	}
+	return out, nil
+}
+
+// -------- helpers --------
+
+// saveInTx persists m and, if its status moved away from `from`, records the
+// transition.
+func saveInTx(tx *gorm.DB, actorID uint, m *JobApplication, from ApplicationStatus) error {
+	if err := tx.Save(m).Error; err != nil {
+		return err
+	}
+	return recordStatusChange(tx, actorID, m, from)
+}
+
+// patchInTx applies a patch to the user's application id within tx. It is
+// the single path for partial updates, shared by Patch and by features that
+// change an application as a side effect.
+func patchInTx(tx *gorm.DB, userID, id uint, patch JobApplicationPatchDto) (JobApplication, error) {
+	var m JobApplication
+	if err := lockOwned(tx, userID, id, &m); err != nil {
+		return JobApplication{}, err
+	}
+
+	from := m.Status
+	PatchModel(&m, patch)
+	if err := validateModel(&m); err != nil {
+		return JobApplication{}, err
+	}
+	if err := checkTransition(from, m.Status); err != nil {
+		return JobApplication{}, err
+	}
+	if patch.Company != nil || patch.CompanyID != nil {
+		if err := linkCompany(tx, userID, &m, patch.CompanyID); err != nil {
+			return JobApplication{}, err
+		}
+	}
+
+	if err := saveInTx(tx, userID, &m, from); err != nil {
+		return JobApplication{}, err
+	}
+	if patch.ContactName != nil || patch.ContactEmail != nil {
+		if err := linkContact(tx, userID, &m); err != nil {
+			return JobApplication{}, err
+		}
+	}
+	return m, nil
+}
+
+// lockOwned loads the user's application id into m and locks its row until
+// tx ends.
+func lockOwned(tx *gorm.DB, userID, id uint, m *JobApplication) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(m, id).Error
+}
+
+func recordStatusChange(tx *gorm.DB, actorID uint, m *JobApplication, from ApplicationStatus) error {
+	if m.Status == from {
+		return nil
+	}
+	return tx.Create(&StatusChange{
+		JobApplicationID: m.ID,
+		FromStatus:       from,
+		ToStatus:         m.Status,
+		ChangedByUserID:  actorID,
+	}).Error
+}
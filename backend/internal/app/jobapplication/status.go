@@ -0,0 +1,80 @@
+package jobapplication
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	ErrUnknownStatus     = errors.New("unknown application status")
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// transitions is the application status state machine: the statuses each
+// status may move to. Rejected and Withdrawn are reachable from every open
+// status; Hired, Rejected and Withdrawn are final.
+var transitions = map[ApplicationStatus][]ApplicationStatus{
+	StatusApplied:   {StatusScreening, StatusRejected, StatusWithdrawn},
+	StatusScreening: {StatusInterview, StatusRejected, StatusWithdrawn},
+	StatusInterview: {StatusOffer, StatusRejected, StatusWithdrawn},
+	StatusOffer:     {StatusHired, StatusRejected, StatusWithdrawn},
+	StatusHired:     {},
+	StatusRejected:  {},
+	StatusWithdrawn: {},
+}
+
+// Known reports whether s is one of the defined statuses.
+func (s ApplicationStatus) Known() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransition reports whether an application may move from one status to
+// another. Staying in the same status is always allowed.
+func CanTransition(from, to ApplicationStatus) bool {
+	if !from.Known() || !to.Known() {
+		return false
+	}
+	return from == to || slices.Contains(transitions[from], to)
+}
+
+func checkTransition(from, to ApplicationStatus) error {
+	if !to.Known() {
+		return ErrUnknownStatus
+	}
+	// Rows written before statuses were checked may hold anything; let them
+	// move to any valid status once.
+	if !from.Known() {
+		return nil
+	}
+	if !CanTransition(from, to) {
+		return ErrInvalidTransition
+	}
+	return nil
+}
+
+// pathTo returns the shortest sequence of statuses that moves an application
+// from one status to another, excluding from itself. It is nil if to cannot
+// be reached or from == to.
+func pathTo(from, to ApplicationStatus) []ApplicationStatus {
+	prev := map[ApplicationStatus]ApplicationStatus{from: ""}
+	queue := []ApplicationStatus{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == to {
+			var path []ApplicationStatus
+			for s := to; s != from; s = prev[s] {
+				path = append([]ApplicationStatus{s}, path...)
+			}
+			return path
+		}
+		for _, next := range transitions[cur] {
+			if _, seen := prev[next]; !seen {
+				prev[next] = cur
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package jobapplication
+
+import (
+	"net/url"
+	"strings"
+
+	"appliedTo/internal/platform/validate"
+)
+
+// validateModel checks a job application after a create, update or patch
+// has been mapped onto it and returns every violation as validate.Errors.
+func validateModel(m *JobApplication) error {
+	var errs validate.Errors
+
+	errs.Required(
+		validate.Field{Name: "title", Value: m.Title},
+		validate.Field{Name: "employment.type", Value: string(m.Employment.Type)},
+		validate.Field{Name: "employment.workLocation", Value: string(m.Employment.WorkLocation)},
+	)
+
+	validate.OneOf(&errs, "status", m.Status, ApplicationStatuses...)
+	if m.Source != "" {
+		validate.OneOf(&errs, "source", m.Source, ApplicationSources...)
+	}
+	if m.Employment.Type != "" {
+		validate.OneOf(&errs, "employment.type", m.Employment.Type, EmploymentTypes...)
+	}
+	if m.Employment.WorkLocation != "" {
+		validate.OneOf(&errs, "employment.workLocation", m.Employment.WorkLocation, WorkLocations...)
+	}
+	if e := m.ContactEmail; e != nil && strings.TrimSpace(*e) != "" && !validate.IsValidEmail(*e) {
+		errs.Add("contactEmail", "must be a valid email address")
+	}
+	if h := m.Employment.HoursPerWeek; h != nil && (*h < 1 || *h > 168) {
+		errs.Add("employment.hoursPerWeek", "must be between 1 and 168")
+	}
+
+	if sr := m.Employment.SalaryRange; sr != nil {
+		validateSalaryRange(&errs, "employment.salaryRange", sr)
+	}
+
+	return errs.Err()
+}
+
+func validateSalaryRange(errs *validate.Errors, prefix string, sr *SalaryRange) {
+	if sr.From < 0 {
+		errs.Add(prefix+".from", "must not be negative")
+	}
+	if sr.To < 0 {
+		errs.Add(prefix+".to", "must not be negative")
+	}
+	if sr.From > sr.To {
+		errs.Add(prefix+".to", "must not be less than from")
+	}
+	errs.Currency(prefix+".currency", sr.Currency)
+	validate.OneOf(errs, prefix+".period", sr.Period, SalaryPeriods...)
+}
+
+// validateInterview checks an interview after a create, update or patch has
+// been mapped onto it.
+func validateInterview(m *Interview) error {
+	var errs validate.Errors
+
+	validate.OneOf(&errs, "type", m.Type, InterviewTypes...)
+	validate.OneOf(&errs, "outcome", m.Outcome, InterviewOutcomes...)
+	if m.ScheduledAt.IsZero() {
+		errs.Add("scheduledAt", "is required")
+	}
+	if m.DurationMinutes < 1 || m.DurationMinutes > 24*60 {
+		errs.Add("durationMinutes", "must be between 1 and 1440")
+	}
+	if m.MeetingURL != nil {
+		if u, err := url.Parse(*m.MeetingURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs.Add("meetingUrl", "must be an http or https URL")
+		}
+	}
+
+	return errs.Err()
+}
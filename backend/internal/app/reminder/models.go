@@ -0,0 +1,30 @@
+package reminder
+
+import "time"
+
+// Reminder tracks the delivery of one due follow-up of a job application.
+type Reminder struct {
+	ID               uint `gorm:"primaryKey"`
+	JobApplicationID uint `gorm:"not null"`
+	DueAt            time.Time
+	State            State `gorm:"type:VARCHAR(16);not null"`
+	Attempts         int
+	ClaimedAt        *time.Time
+	SentAt           *time.Time
+	LastError        *string
+	CreatedAt        time.Time
+}
+
+func (Reminder) TableName() string { return "follow_up_reminders" }
+
+type State string
+
+const (
+	StatePending State = "pending"
+	StateSending State = "sending"
+	StateSent    State = "sent"
+	StateFailed  State = "failed"
+	// StateSkipped marks reminders whose follow-up was moved or cleared, or
+	// whose application was deleted or closed, before they were delivered.
+	StateSkipped State = "skipped"
+)
@@ -0,0 +1,216 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"appliedTo/internal/app/jobapplication"
+	"appliedTo/internal/platform/notify"
+)
+
+type Config struct {
+	// Interval between two scans for due follow-ups.
+	Interval time.Duration
+	// Lookback bounds how old a due follow-up may be and still be reminded
+	// of, so enabling the scheduler does not flood users with stale ones.
+	Lookback time.Duration
+	// BatchSize caps the reminders one replica claims per tick.
+	BatchSize int
+	// MaxAttempts before a reminder is given up as failed.
+	MaxAttempts int
+	// ClaimTimeout after which a reminder stuck in "sending" (its replica
+	// died mid-delivery) may be claimed again.
+	ClaimTimeout time.Duration
+}
+
+func (c *Config) setDefaults() {
+	if c.Interval <= 0 {
+		c.Interval = time.Minute
+	}
+	if c.Lookback <= 0 {
+		c.Lookback = 7 * 24 * time.Hour
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 50
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.ClaimTimeout <= 0 {
+		c.ClaimTimeout = 10 * time.Minute
+	}
+}
+
+// Scheduler turns passed NextFollowUpAt timestamps into reminders and
+// delivers them through a Notifier.
+//
+// Every replica may run one. A follow-up is enqueued at most once thanks to
+// the (job_application_id, due_at) unique key, and each reminder is claimed
+// by exactly one replica with FOR UPDATE SKIP LOCKED before it is sent.
+// Deliveries of a batch give up once its claim could time out, and an
+// outcome is only recorded by the replica holding the current claim. A
+// reminder is only sent twice if a replica dies between sending it and
+// recording the delivery.
+type Scheduler struct {
+	db       *gorm.DB
+	notifier notify.Notifier
+	cfg      Config
+}
+
+func NewScheduler(db *gorm.DB, notifier notify.Notifier, cfg Config) *Scheduler {
+	cfg.setDefaults()
+	return &Scheduler{db: db, notifier: notifier, cfg: cfg}
+}
+
+// Run ticks until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	t := time.NewTicker(s.cfg.Interval)
+	defer t.Stop()
+	for {
+		if err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("reminder: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Tick enqueues newly due follow-ups and delivers one batch of reminders.
+func (s *Scheduler) Tick(ctx context.Context) error {
+	if err := s.enqueue(ctx); err != nil {
+		return fmt.Errorf("enqueue: %w", err)
+	}
+	claimedAt := time.Now()
+	due, err := s.claim(ctx, claimedAt)
+	if err != nil {
+		return fmt.Errorf("claim: %w", err)
+	}
+	// Stop sending before another replica may claim the batch again.
+	ctx, cancel := context.WithDeadline(ctx, claimedAt.Add(s.cfg.ClaimTimeout))
+	defer cancel()
+	for _, d := range due {
+		s.deliver(ctx, d)
+	}
+	return nil
+}
+
+func (s *Scheduler) enqueue(ctx context.Context) error {
+	now := time.Now()
+	return s.db.WithContext(ctx).Exec(`
+		INSERT INTO follow_up_reminders (job_application_id, due_at)
+		SELECT id, next_follow_up_at FROM job_applications
+		WHERE next_follow_up_at <= ? AND next_follow_up_at > ?
+		  AND deleted_at IS NULL
+		  AND status NOT IN ?
+		ON CONFLICT ON CONSTRAINT uniq_follow_up_reminders_due DO NOTHING`,
+		now, now.Add(-s.cfg.Lookback), closedStatuses,
+	).Error
+}
+
+var closedStatuses = []jobapplication.ApplicationStatus{
+	jobapplication.StatusHired, jobapplication.StatusRejected, jobapplication.StatusWithdrawn,
+}
+
+// dueReminder is a claimed reminder joined with what the message needs.
+type dueReminder struct {
+	ID             uint
+	DueAt          time.Time
+	Attempts       int
+	ClaimedAt      time.Time
+	NextFollowUpAt *time.Time
+	Status         jobapplication.ApplicationStatus
+	DeletedAt      *time.Time
+	Company        string
+	Title          string
+	Email          string
+	FirstName      string
+}
+
+// claim atomically moves a batch of pending (or abandoned) reminders to
+// "sending" and returns them. Rows locked by other replicas are skipped.
+func (s *Scheduler) claim(ctx context.Context, now time.Time) ([]dueReminder, error) {
+	var ids []uint
+	err := s.db.WithContext(ctx).Raw(`
+		UPDATE follow_up_reminders SET state = ?, claimed_at = ?, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM follow_up_reminders
+			WHERE (state = ? OR (state = ? AND claimed_at < ?))
+			ORDER BY due_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		StateSending, now, StatePending, StateSending, now.Add(-s.cfg.ClaimTimeout), s.cfg.BatchSize,
+	).Scan(&ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var due []dueReminder
+	err = s.db.WithContext(ctx).Raw(`
+		SELECT r.id, r.due_at, r.attempts, r.claimed_at, ja.next_follow_up_at, ja.status, ja.deleted_at, ja.company, ja.title, u.email, u.first_name
+		FROM follow_up_reminders r
+		JOIN job_applications ja ON ja.id = r.job_application_id
+		JOIN users u ON u.id = ja.user_id
+		WHERE r.id IN ?
+		ORDER BY r.due_at`, ids,
+	).Scan(&due).Error
+	return due, err
+}
+
+func (s *Scheduler) deliver(ctx context.Context, d dueReminder) {
+	if d.NextFollowUpAt == nil || !d.NextFollowUpAt.Equal(d.DueAt) ||
+		d.DeletedAt != nil || slices.Contains(closedStatuses, d.Status) {
+		s.finish(ctx, d, map[string]any{"state": StateSkipped})
+		return
+	}
+
+	if err := s.notifier.Notify(ctx, message(d)); err != nil {
+		state := StatePending
+		if d.Attempts >= s.cfg.MaxAttempts {
+			state = StateFailed
+		}
+		log.Printf("reminder %d: attempt %d: %v", d.ID, d.Attempts, err)
+		s.finish(ctx, d, map[string]any{"state": state, "last_error": err.Error()})
+		return
+	}
+	s.finish(ctx, d, map[string]any{"state": StateSent, "sent_at": time.Now(), "last_error": nil})
+}
+
+// finish records the outcome of delivering d, unless another replica has
+// claimed the reminder since.
+func (s *Scheduler) finish(ctx context.Context, d dueReminder, updates map[string]any) {
+	// Record the outcome even if ctx was cancelled mid-delivery.
+	err := s.db.WithContext(context.WithoutCancel(ctx)).
+		Model(&Reminder{}).Where("id = ? AND state = ? AND claimed_at = ?", d.ID, StateSending, d.ClaimedAt).
+		Updates(updates).Error
+	if err != nil {
+		log.Printf("reminder %d: record outcome: %v", d.ID, err)
+	}
+}
+
+func message(d dueReminder) notify.Message {
+	what := d.Title
+	if d.Company != "" {
+		what = fmt.Sprintf("%s at %s", d.Title, d.Company)
+	}
+	name := strings.TrimSpace(d.FirstName)
+	if name == "" {
+		name = "there"
+	}
+	return notify.Message{
+		To:      d.Email,
+		Subject: "Follow up: " + what,
+		Body: fmt.Sprintf("Hi %s,\n\nyou planned to follow up on your application for %s on %s.\n\nGood luck!\nAppliedTo",
+			name, what, d.DueAt.UTC().Format("Mon, 02 Jan 2006 15:04 MST")),
+	}
+}
@@ -0,0 +1,12 @@
+package salaryapi
+
+import (
+	"net/http"
+
+	"appliedTo/internal/app/salary"
+	"appliedTo/internal/platform/http/problem"
+)
+
+func init() {
+	problem.Register(salary.ErrUnknownCurrency, http.StatusBadRequest, "unknown_currency", "No exchange rate for currency")
+}
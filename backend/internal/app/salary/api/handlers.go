@@ -0,0 +1,63 @@
+package salaryapi
+
+import (
+	"appliedTo/internal/app/salary"
+	"appliedTo/internal/platform/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handlers struct {
+	Svc *salary.Service
+}
+
+func NewHandlers(s *salary.Service) *Handlers { return &Handlers{Svc: s} }
+
+// @Summary List exchange rates
+// @Description Returns the exchange rates used to normalize salaries, as units of each currency per one unit of base.
+// @Tags salary
+// @Security BearerAuth
+// @Produce  json
+// @Param   base  query  string  false  "Currency the rates are relative to (default: base currency)"
+// @Success 200 {object} salary.RatesDto "Exchange rates"
+// @Failure 400 {object} problem.Problem "Invalid or unknown currency"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /exchange_rates [get]
+func (h *Handlers) GetRates(c *gin.Context) {
+	out, err := h.Svc.Rates(c.Request.Context(), c.Query("base"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// @Summary Replace exchange rates
+// @Description Replaces every exchange rate. Rates are units of each currency per one unit of base.
+// @Tags admin
+// @Security BearerAuth
+// @Accept  json
+// @Produce  json
+// @Param   rates  body  salary.RatesDto  true  "Exchange rates"
+// @Success 200 {object} salary.RatesDto "Exchange rates"
+// @Failure 400 {object} problem.Problem "Invalid input"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 403 {object} problem.Problem "Admin role required"
+// @Failure 500 {object} problem.Problem "Could not store rates"
+// @Router /admin/exchange_rates [put]
+func (h *Handlers) ReplaceRates(c *gin.Context) {
+	var in salary.RatesDto
+	if err := c.ShouldBindJSON(&in); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.ReplaceRates(c.Request.Context(), in)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
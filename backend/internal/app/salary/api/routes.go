@@ -0,0 +1,27 @@
+package salaryapi
+
+import (
+	"appliedTo/internal/platform/http/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupExchangeRateRoutes(h *Handlers, requireAuth gin.HandlerFunc) routes.RouteConfig {
+	return routes.RouteConfig{
+		Prefix: "/exchange_rates",
+		Use:    []gin.HandlerFunc{requireAuth},
+		Register: func(g *gin.RouterGroup) {
+			g.GET("", h.GetRates)
+		},
+	}
+}
+
+func SetupAdminExchangeRateRoutes(h *Handlers, opts routes.AdminRouteOpts) routes.RouteConfig {
+	return routes.RouteConfig{
+		Prefix: "/admin/exchange_rates",
+		Use:    []gin.HandlerFunc{opts.RequireAuth, opts.RequireAdmin},
+		Register: func(g *gin.RouterGroup) {
+			g.PUT("", h.ReplaceRates)
+		},
+	}
+}
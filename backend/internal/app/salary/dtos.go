@@ -0,0 +1,28 @@
+package salary
+
+import "time"
+
+// RatesDto lists exchange rates as units of each currency per one unit of
+// Base. It is also the format of the rates file.
+type RatesDto struct {
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	UpdatedAt *time.Time         `json:"updatedAt,omitempty"`
+}
+
+// Range is a salary range as quoted. HoursPerWeek is the position's, if
+// known.
+type Range struct {
+	From         int
+	To           int
+	Currency     string
+	Period       string
+	HoursPerWeek *int
+}
+
+// AnnualDto is a salary range normalized to a yearly amount.
+type AnnualDto struct {
+	From     float64 `json:"from"`
+	To       float64 `json:"to"`
+	Currency string  `json:"currency"`
+}
@@ -0,0 +1,26 @@
+package salary
+
+import "time"
+
+// ExchangeRate is the value of one unit of the rates' common reference
+// currency in Currency. Only ratios between rows are meaningful, so the
+// reference itself is not stored.
+type ExchangeRate struct {
+	Currency  string    `gorm:"primaryKey;type:CHAR(3)"`
+	Rate      float64   `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// Periods a salary can be quoted in, as stored on job applications.
+const (
+	PerYear  = "Year"
+	PerMonth = "Month"
+	PerWeek  = "Week"
+	PerDay   = "Day"
+	PerHour  = "Hour"
+)
+
+const (
+	weeksPerYear = 52
+	daysPerWeek  = 5
+)
@@ -0,0 +1,106 @@
+package salary
+
+import (
+	"math"
+	"strconv"
+)
+
+// Normalizer converts salary ranges to yearly amounts in one currency. A
+// yearly salary has 52 weeks of five working days; hourly and daily salaries
+// use the position's hours per week, or the configured full-time week.
+type Normalizer struct {
+	currency     string
+	hoursPerWeek float64
+	rates        map[string]float64
+}
+
+// Currency is the currency amounts are normalized to.
+func (n Normalizer) Currency() string { return n.currency }
+
+// Annual normalizes r. It reports false when r has no amount, an unknown
+// period or a currency without exchange rate.
+func (n Normalizer) Annual(r Range) (AnnualDto, bool) {
+	if r.From <= 0 {
+		return AnnualDto{}, false
+	}
+	factor, ok := n.periodFactor(r.Period, r.HoursPerWeek)
+	if !ok {
+		return AnnualDto{}, false
+	}
+	to := r.To
+	if to < r.From {
+		to = r.From
+	}
+	from, ok := n.convert(float64(r.From)*factor, r.Currency)
+	if !ok {
+		return AnnualDto{}, false
+	}
+	upper, _ := n.convert(float64(to)*factor, r.Currency)
+	return AnnualDto{From: math.Round(from), To: math.Round(upper), Currency: n.currency}, true
+}
+
+func (n Normalizer) periodFactor(period string, hoursPerWeek *int) (float64, bool) {
+	hours := n.hoursPerWeek
+	if hoursPerWeek != nil && *hoursPerWeek > 0 {
+		hours = float64(*hoursPerWeek)
+	}
+	switch period {
+	case PerYear:
+		return 1, true
+	case PerMonth:
+		return 12, true
+	case PerWeek:
+		return weeksPerYear, true
+	case PerDay:
+		// A part-time week has proportionally fewer working days.
+		return hours * daysPerWeek * weeksPerYear / n.hoursPerWeek, true
+	case PerHour:
+		return hours * weeksPerYear, true
+	}
+	return 0, false
+}
+
+func (n Normalizer) convert(amount float64, from string) (float64, bool) {
+	if from == n.currency {
+		return amount, true
+	}
+	src, target := n.rates[from], n.rates[n.currency]
+	if src <= 0 || target <= 0 {
+		return 0, false
+	}
+	return amount / src * target, true
+}
+
+// Columns names the SQL expressions a salary range is stored in.
+type Columns struct {
+	Amount       string
+	Currency     string
+	Period       string
+	HoursPerWeek string
+}
+
+// AnnualSQL returns a double precision SQL expression for the yearly amount
+// in the normalizer's currency, NULL where Annual would report false, and
+// the values of its placeholders. It computes what Annual does before
+// rounding.
+func (n Normalizer) AnnualSQL(c Columns) (string, []any) {
+	full := strconv.FormatFloat(n.hoursPerWeek, 'f', -1, 64)
+	hours := "CAST(COALESCE(NULLIF(" + c.HoursPerWeek + ", 0), " + full + ") AS double precision)"
+	factor := "CASE " + c.Period +
+		" WHEN '" + PerYear + "' THEN 1" +
+		" WHEN '" + PerMonth + "' THEN 12" +
+		" WHEN '" + PerWeek + "' THEN " + strconv.Itoa(weeksPerYear) +
+		" WHEN '" + PerDay + "' THEN " + hours + " * " + strconv.Itoa(daysPerWeek*weeksPerYear) + " / " + full +
+		" WHEN '" + PerHour + "' THEN " + hours + " * " + strconv.Itoa(weeksPerYear) +
+		" END"
+
+	var target any
+	if r, ok := n.rates[n.currency]; ok {
+		target = r
+	}
+	rate := "CASE WHEN " + c.Currency + " = ? THEN 1" +
+		" ELSE ? / (SELECT er.rate FROM exchange_rates er WHERE er.currency = " + c.Currency + ") END"
+
+	expr := "(CAST(NULLIF(GREATEST(" + c.Amount + ", 0), 0) AS double precision) * " + factor + " * " + rate + ")"
+	return expr, []any{n.currency, target}
+}
@@ -0,0 +1,221 @@
+package salary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"appliedTo/internal/platform/validate"
+)
+
+const (
+	defaultBaseCurrency = "USD"
+	defaultHoursPerWeek = 40
+	// ratesTTL bounds how long another replica's rate update can go unseen.
+	ratesTTL = time.Minute
+)
+
+var ErrUnknownCurrency = errors.New("no exchange rate for currency")
+
+type Service struct {
+	db           *gorm.DB
+	base         string
+	hoursPerWeek float64
+
+	mu        sync.Mutex
+	rates     map[string]float64
+	updatedAt *time.Time
+	fetchedAt time.Time
+}
+
+// ---- Options pattern ----
+
+type Option func(*Service)
+
+// WithBaseCurrency sets the currency salaries are normalized to by default.
+func WithBaseCurrency(code string) Option {
+	return func(s *Service) {
+		if code = strings.ToUpper(strings.TrimSpace(code)); validate.IsCurrency(code) {
+			s.base = code
+		}
+	}
+}
+
+// WithHoursPerWeek sets the full-time week used for hourly and daily
+// salaries of positions without their own hours per week.
+func WithHoursPerWeek(h float64) Option {
+	return func(s *Service) {
+		if h > 0 {
+			s.hoursPerWeek = h
+		}
+	}
+}
+
+func NewService(db *gorm.DB, opts ...Option) *Service {
+	s := &Service{db: db, base: defaultBaseCurrency, hoursPerWeek: defaultHoursPerWeek}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// BaseCurrency is the currency salaries are normalized to by default.
+func (s *Service) BaseCurrency() string { return s.base }
+
+// Normalizer returns a normalizer to the given currency, or to the base
+// currency if it is empty. Without an exchange rate for the currency only
+// salaries already quoted in it can be normalized, which is an error unless
+// it is the base currency.
+func (s *Service) Normalizer(ctx context.Context, currency string) (Normalizer, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = s.base
+	}
+	if !validate.IsCurrency(currency) {
+		return Normalizer{}, validate.Errors{{Field: "salaryCurrency", Message: "must be an ISO 4217 currency code"}}
+	}
+	rates, _, err := s.cached(ctx)
+	if err != nil {
+		return Normalizer{}, err
+	}
+	if _, ok := rates[currency]; !ok && currency != s.base {
+		return Normalizer{}, ErrUnknownCurrency
+	}
+	return Normalizer{currency: currency, hoursPerWeek: s.hoursPerWeek, rates: rates}, nil
+}
+
+// READ
+// Rates returns the exchange rates relative to base, or to the base
+// currency if it is empty.
+func (s *Service) Rates(ctx context.Context, base string) (RatesDto, error) {
+	n, err := s.Normalizer(ctx, base)
+	if err != nil {
+		return RatesDto{}, err
+	}
+	_, updatedAt, err := s.cached(ctx)
+	if err != nil {
+		return RatesDto{}, err
+	}
+	out := RatesDto{Base: n.currency, Rates: make(map[string]float64, len(n.rates)), UpdatedAt: updatedAt}
+	for code := range n.rates {
+		if r, ok := n.convert(1, code); ok {
+			out.Rates[code] = round(r, 6)
+		}
+	}
+	return out, nil
+}
+
+// UPDATE (full replace)
+// ReplaceRates replaces every exchange rate with the given ones.
+func (s *Service) ReplaceRates(ctx context.Context, in RatesDto) (RatesDto, error) {
+	rows, err := validateRates(in)
+	if err != nil {
+		return RatesDto{}, err
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&ExchangeRate{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return RatesDto{}, err
+	}
+
+	s.mu.Lock()
+	s.fetchedAt = time.Time{}
+	s.mu.Unlock()
+	return s.Rates(ctx, in.Base)
+}
+
+// LoadFile replaces the exchange rates with those of a JSON file in the
+// RatesDto format.
+func (s *Service) LoadFile(ctx context.Context, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var in RatesDto
+	if err := json.Unmarshal(b, &in); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if _, err := s.ReplaceRates(ctx, in); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// -------- helpers --------
+
+// cached returns the exchange rates, reloading them when they are older
+// than ratesTTL. The returned map must not be modified.
+func (s *Service) cached(ctx context.Context) (map[string]float64, *time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rates != nil && time.Since(s.fetchedAt) < ratesTTL {
+		return s.rates, s.updatedAt, nil
+	}
+
+	var rows []ExchangeRate
+	if err := s.db.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	rates := make(map[string]float64, len(rows))
+	var updatedAt *time.Time
+	for _, r := range rows {
+		rates[r.Currency] = r.Rate
+		if updatedAt == nil || r.UpdatedAt.After(*updatedAt) {
+			t := r.UpdatedAt.UTC()
+			updatedAt = &t
+		}
+	}
+	s.rates, s.updatedAt, s.fetchedAt = rates, updatedAt, time.Now()
+	return rates, updatedAt, nil
+}
+
+func validateRates(in RatesDto) ([]ExchangeRate, error) {
+	var errs validate.Errors
+	base := strings.ToUpper(strings.TrimSpace(in.Base))
+	if !validate.IsCurrency(base) {
+		errs.Add("base", "must be an ISO 4217 currency code")
+	}
+	if len(in.Rates) == 0 {
+		errs.Add("rates", "is required")
+	}
+
+	rows := []ExchangeRate{{Currency: base, Rate: 1}}
+	seen := map[string]bool{}
+	for code, rate := range in.Rates {
+		field := "rates." + code
+		code = strings.ToUpper(strings.TrimSpace(code))
+		switch {
+		case !validate.IsCurrency(code):
+			errs.Add(field, "must be keyed by an ISO 4217 currency code")
+		case seen[code]:
+			errs.Add(field, "is given more than once")
+		case rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate):
+			errs.Add(field, "must be a positive number")
+		case code == base:
+			if rate != 1 {
+				errs.Add(field, "must be 1 for the base currency")
+			}
+		default:
+			rows = append(rows, ExchangeRate{Currency: code, Rate: rate})
+		}
+		seen[code] = true
+	}
+	return rows, errs.Err()
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow10(places)
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,49 @@
+package statsapi
+
+import (
+	"appliedTo/internal/app/stats"
+	"appliedTo/internal/platform/http/middleware"
+	"appliedTo/internal/platform/http/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handlers struct {
+	Svc *stats.Service
+}
+
+func NewHandlers(s *stats.Service) *Handlers { return &Handlers{Svc: s} }
+
+// @Summary Pipeline analytics
+// @Description Funnel metrics over the caller's job applications: counts per status, stage-to-stage conversion, median days from appliedAt to the first response, response rates by source and work location, weekly application volume and the distribution of yearly salaries, converted to salaryCurrency. A response is a status change other than to Applied or Withdrawn, or a received email, call, interview or offer activity.
+// @Tags stats
+// @Security BearerAuth
+// @Produce  json
+// @Param   from            query  string  false  "First day of the weekly volume (YYYY-MM-DD, default 11 weeks before to)"
+// @Param   to              query  string  false  "Last day of the weekly volume (YYYY-MM-DD, default today)"
+// @Param   salaryCurrency  query  string  false  "Currency of the salary distribution (default: base currency)"
+// @Success 200 {object} stats.StatsDto "Statistics"
+// @Failure 400 {object} problem.Problem "Invalid date range or currency"
+// @Failure 401 {object} problem.Problem "Authentication required"
+// @Failure 500 {object} problem.Problem "Database query failed"
+// @Router /stats [get]
+func (h *Handlers) GetStats(c *gin.Context) {
+	userID, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var q stats.StatsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.Get(c.Request.Context(), userID, q)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
@@ -0,0 +1,17 @@
+package statsapi
+
+import (
+	"appliedTo/internal/platform/http/routes"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupStatsRoutes(h *Handlers, requireAuth gin.HandlerFunc) routes.RouteConfig {
+	return routes.RouteConfig{
+		Prefix: "/stats",
+		Use:    []gin.HandlerFunc{requireAuth},
+		Register: func(g *gin.RouterGroup) {
+			g.GET("", h.GetStats)
+		},
+	}
+}
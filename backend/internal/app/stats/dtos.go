@@ -0,0 +1,66 @@
+package stats
+
+import "time"
+
+// StatsQuery selects the weeks of the weekly volume. Both bounds are dates
+// and default to the last twelve weeks. Salaries are converted to
+// SalaryCurrency, by default the base currency.
+type StatsQuery struct {
+	From           *time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To             *time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	SalaryCurrency string     `form:"salaryCurrency"`
+}
+
+type StatsDto struct {
+	Total        int64            `json:"total"`
+	StatusCounts map[string]int64 `json:"statusCounts"`
+	// Funnel counts the applications that reached each stage, including
+	// those that were later rejected or withdrawn.
+	Funnel []FunnelStageDto `json:"funnel"`
+	// MedianDaysToFirstResponse is nil until some application with an
+	// AppliedAt has had a response.
+	MedianDaysToFirstResponse  *float64          `json:"medianDaysToFirstResponse"`
+	ResponseRate               float64           `json:"responseRate"`
+	ResponseRateBySource       []ResponseRateDto `json:"responseRateBySource"`
+	ResponseRateByWorkLocation []ResponseRateDto `json:"responseRateByWorkLocation"`
+	WeeklyVolume               WeeklyVolumeDto   `json:"weeklyVolume"`
+	// Salary summarizes the midpoints of the salary ranges as yearly
+	// amounts. It is nil when no application has a comparable salary.
+	Salary *SalaryDistributionDto `json:"salary"`
+}
+
+type FunnelStageDto struct {
+	Stage   string `json:"stage"`
+	Reached int64  `json:"reached"`
+	// ConversionRate is the share of the previous stage that reached this
+	// one; nil for the first stage or when the previous stage is empty.
+	ConversionRate *float64 `json:"conversionRate"`
+}
+
+type ResponseRateDto struct {
+	Key          string  `json:"key"`
+	Applications int64   `json:"applications"`
+	Responses    int64   `json:"responses"`
+	Rate         float64 `json:"rate"`
+}
+
+type WeeklyVolumeDto struct {
+	From  time.Time      `json:"from"`
+	To    time.Time      `json:"to"`
+	Weeks []WeekCountDto `json:"weeks"`
+}
+
+type WeekCountDto struct {
+	WeekStart    time.Time `json:"weekStart"`
+	Applications int64     `json:"applications"`
+}
+
+type SalaryDistributionDto struct {
+	Currency     string  `json:"currency"`
+	Applications int64   `json:"applications"`
+	Min          float64 `json:"min"`
+	P25          float64 `json:"p25"`
+	Median       float64 `json:"median"`
+	P75          float64 `json:"p75"`
+	Max          float64 `json:"max"`
+}
@@ -0,0 +1,302 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"appliedTo/internal/app/jobapplication"
+	"appliedTo/internal/app/salary"
+	"appliedTo/internal/platform/validate"
+)
+
+const (
+	defaultWeeks = 12
+	maxWeeks     = 520
+)
+
+// funnel is the order in which applications move through the pipeline.
+// Rejected and Withdrawn end an application at whatever stage it reached.
+var funnel = []jobapplication.ApplicationStatus{jobapplication.StatusApplied, jobapplication.StatusScreening, jobapplication.StatusInterview, jobapplication.StatusOffer, jobapplication.StatusHired}
+
+// responseActivities are the activities that show the company answered.
+var responseActivities = []jobapplication.ActivityType{jobapplication.ActivityEmailReceived, jobapplication.ActivityCall, jobapplication.ActivityInterview, jobapplication.ActivityOfferReceived}
+
+type Service struct {
+	db       *gorm.DB
+	salaries *salary.Service
+}
+
+func NewService(db *gorm.DB, salaries *salary.Service) *Service {
+	return &Service{db: db, salaries: salaries}
+}
+
+// Get computes the pipeline metrics of the user's applications. Everything
+// is aggregated in the database.
+func (s *Service) Get(ctx context.Context, userID uint, q StatsQuery) (StatsDto, error) {
+	from, to, err := weekRange(q, time.Now())
+	if err != nil {
+		return StatsDto{}, err
+	}
+	n, err := s.salaries.Normalizer(ctx, q.SalaryCurrency)
+	if err != nil {
+		return StatsDto{}, err
+	}
+
+	db := s.db.WithContext(ctx)
+	args := map[string]any{"user": userID}
+	out := StatsDto{StatusCounts: make(map[string]int64, len(jobapplication.ApplicationStatuses))}
+
+	if err := s.statusCounts(db, args, &out); err != nil {
+		return StatsDto{}, err
+	}
+	if err := s.funnel(db, args, &out); err != nil {
+		return StatsDto{}, err
+	}
+	if err := s.responses(db, args, &out); err != nil {
+		return StatsDto{}, err
+	}
+	if out.WeeklyVolume, err = s.weeklyVolume(db, userID, from, to); err != nil {
+		return StatsDto{}, err
+	}
+	if out.Salary, err = s.salary(db, userID, n); err != nil {
+		return StatsDto{}, err
+	}
+	return out, nil
+}
+
+// appsCTE has one row per application of @user with the furthest funnel
+// stage it reached (1 for Applied) and when the company first responded: a
+// status change other than to Applied or Withdrawn, or a response activity,
+// no earlier than AppliedAt.
+var appsCTE = func() string {
+	var rank strings.Builder
+	rank.WriteString("CASE %s")
+	for i, st := range funnel {
+		fmt.Fprintf(&rank, " WHEN '%s' THEN %d", st, i+1)
+	}
+	rank.WriteString(" ELSE 0 END")
+
+	return `WITH apps AS (
+	SELECT ja.id, ja.status,
+		COALESCE(NULLIF(ja.source, ''), 'Unspecified') AS source,
+		COALESCE(NULLIF(ja.employment_work_location, ''), 'Unspecified') AS work_location,
+		ja.applied_at,
+		GREATEST(1, ` + fmt.Sprintf(rank.String(), "ja.status") + `, COALESCE((
+			SELECT MAX(` + fmt.Sprintf(rank.String(), "sc.to_status") + `)
+			FROM status_changes sc WHERE sc.job_application_id = ja.id
+		), 0)) AS stage,
+		(
+			SELECT MIN(r.responded_at) FROM (
+				SELECT sc.changed_at AS responded_at FROM status_changes sc
+				WHERE sc.job_application_id = ja.id AND sc.to_status NOT IN ('` + string(jobapplication.StatusApplied) + `', '` + string(jobapplication.StatusWithdrawn) + `')
+				UNION ALL
+				SELECT a.occurred_at FROM activities a
+				WHERE a.job_application_id = ja.id AND a.type IN (` + quoted(responseActivities) + `)
+			) r
+			WHERE r.responded_at >= COALESCE(ja.applied_at, '-infinity')
+		) AS first_response_at
+	FROM job_applications ja
+	WHERE ja.user_id = @user AND ja.deleted_at IS NULL
+)
+`
+}()
+
+func (s *Service) statusCounts(db *gorm.DB, args map[string]any, out *StatsDto) error {
+	for _, st := range jobapplication.ApplicationStatuses {
+		out.StatusCounts[string(st)] = 0
+	}
+	var rows []struct {
+		Status string
+		N      int64
+	}
+	if err := db.Raw(`SELECT status, count(*) AS n FROM job_applications
+		WHERE user_id = @user AND deleted_at IS NULL GROUP BY status`, args).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	for _, r := range rows {
+		out.StatusCounts[r.Status] = r.N
+		out.Total += r.N
+	}
+	return nil
+}
+
+func (s *Service) funnel(db *gorm.DB, args map[string]any, out *StatsDto) error {
+	var rows []struct {
+		Stage int
+		N     int64
+	}
+	if err := db.Raw(appsCTE+`SELECT stage, count(*) AS n FROM apps GROUP BY stage`, args).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	// An application that reached a stage reached every stage before it.
+	reached := make([]int64, len(funnel))
+	for _, r := range rows {
+		for i := 0; i < r.Stage && i < len(funnel); i++ {
+			reached[i] += r.N
+		}
+	}
+	out.Funnel = make([]FunnelStageDto, len(funnel))
+	for i, st := range funnel {
+		out.Funnel[i] = FunnelStageDto{Stage: string(st), Reached: reached[i]}
+		if i > 0 && reached[i-1] > 0 {
+			r := rate(reached[i], reached[i-1])
+			out.Funnel[i].ConversionRate = &r
+		}
+	}
+	return nil
+}
+
+func (s *Service) responses(db *gorm.DB, args map[string]any, out *StatsDto) error {
+	var median sql.NullFloat64
+	if err := db.Raw(appsCTE+`SELECT percentile_cont(0.5) WITHIN GROUP (
+			ORDER BY EXTRACT(EPOCH FROM first_response_at - applied_at) / 86400
+		) FROM apps WHERE applied_at IS NOT NULL AND first_response_at IS NOT NULL`, args).
+		Row().Scan(&median); err != nil {
+		return err
+	}
+	if median.Valid {
+		d := math.Round(median.Float64*10) / 10
+		out.MedianDaysToFirstResponse = &d
+	}
+
+	var rows []struct {
+		Dimension    string
+		Key          string
+		Applications int64
+		Responses    int64
+	}
+	if err := db.Raw(appsCTE+`SELECT 'source' AS dimension, source AS key,
+			count(*) AS applications, count(first_response_at) AS responses
+		FROM apps GROUP BY source
+		UNION ALL
+		SELECT 'workLocation', work_location, count(*), count(first_response_at)
+		FROM apps GROUP BY work_location
+		ORDER BY dimension, key`, args).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	var total, responded int64
+	out.ResponseRateBySource = []ResponseRateDto{}
+	out.ResponseRateByWorkLocation = []ResponseRateDto{}
+	for _, r := range rows {
+		dto := ResponseRateDto{Key: r.Key, Applications: r.Applications, Responses: r.Responses, Rate: rate(r.Responses, r.Applications)}
+		if r.Dimension == "source" {
+			out.ResponseRateBySource = append(out.ResponseRateBySource, dto)
+			total += r.Applications
+			responded += r.Responses
+		} else {
+			out.ResponseRateByWorkLocation = append(out.ResponseRateByWorkLocation, dto)
+		}
+	}
+	out.ResponseRate = rate(responded, total)
+	return nil
+}
+
+// weeklyVolume counts applications per week by AppliedAt, or CreatedAt for
+// applications without one. Weeks start on Monday (UTC).
+func (s *Service) weeklyVolume(db *gorm.DB, userID uint, from, to time.Time) (WeeklyVolumeDto, error) {
+	out := WeeklyVolumeDto{From: from, To: to.AddDate(0, 0, 6), Weeks: []WeekCountDto{}}
+	err := db.Raw(`SELECT w AS week_start, count(ja.id) AS applications
+		FROM generate_series(@from::timestamptz, @to::timestamptz, interval '1 week') w
+		LEFT JOIN job_applications ja ON ja.user_id = @user AND ja.deleted_at IS NULL
+			AND COALESCE(ja.applied_at, ja.created_at) >= w
+			AND COALESCE(ja.applied_at, ja.created_at) < w + interval '1 week'
+		GROUP BY w ORDER BY w`, map[string]any{"user": userID, "from": from, "to": to}).
+		Scan(&out.Weeks).Error
+	for i := range out.Weeks {
+		out.Weeks[i].WeekStart = out.Weeks[i].WeekStart.UTC()
+	}
+	return out, err
+}
+
+// salary summarizes the midpoints of the salary ranges, normalized by n.
+func (s *Service) salary(db *gorm.DB, userID uint, n salary.Normalizer) (*SalaryDistributionDto, error) {
+	amount, args := n.AnnualSQL(salary.Columns{
+		Amount:       "(employment_salary_range_from + GREATEST(employment_salary_range_from, employment_salary_range_to)) / 2.0",
+		Currency:     "employment_salary_range_currency",
+		Period:       "employment_salary_range_period",
+		HoursPerWeek: "employment_hours_per_week",
+	})
+
+	var row struct {
+		Applications int64
+		Min, P25, Median, P75, Max *float64
+	}
+	err := db.Raw(`WITH s AS (
+			SELECT `+amount+` AS amount
+			FROM job_applications
+			WHERE user_id = ? AND deleted_at IS NULL AND employment_salary_range_from > 0
+		)
+		SELECT count(amount) AS applications, min(amount) AS min,
+			percentile_cont(0.25) WITHIN GROUP (ORDER BY amount) AS p25,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY amount) AS median,
+			percentile_cont(0.75) WITHIN GROUP (ORDER BY amount) AS p75,
+			max(amount) AS max
+		FROM s`, append(args, userID)...).
+		Scan(&row).Error
+	if err != nil || row.Applications == 0 {
+		return nil, err
+	}
+	return &SalaryDistributionDto{
+		Currency:     n.Currency(),
+		Applications: row.Applications,
+		Min:          math.Round(*row.Min),
+		P25:          math.Round(*row.P25),
+		Median:       math.Round(*row.Median),
+		P75:          math.Round(*row.P75),
+		Max:          math.Round(*row.Max),
+	}, nil
+}
+
+// weekRange returns the Mondays starting the first and last week of the
+// requested range.
+func weekRange(q StatsQuery, now time.Time) (time.Time, time.Time, error) {
+	to := now.UTC()
+	if q.To != nil {
+		to = q.To.UTC()
+	}
+	from := to.AddDate(0, 0, -7*(defaultWeeks-1))
+	if q.From != nil {
+		from = q.From.UTC()
+	}
+	from, to = monday(from), monday(to)
+
+	var errs validate.Errors
+	switch {
+	case from.After(to):
+		errs.Add("from", "must not be after to")
+	case to.Sub(from) > maxWeeks*7*24*time.Hour:
+		errs.Add("to", fmt.Sprintf("must be within %d weeks of from", maxWeeks))
+	}
+	return from, to, errs.Err()
+}
+
+func monday(t time.Time) time.Time {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
+
+func rate(n, of int64) float64 {
+	if of == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(of)*10000) / 10000
+}
+
+func quoted[T ~string](values []T) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = "'" + string(v) + "'"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,15 @@
+package userapi
+
+import (
+	"net/http"
+
+	"appliedTo/internal/app/user"
+	"appliedTo/internal/platform/http/problem"
+)
+
+func init() {
+	problem.Register(user.ErrEmailInUse, http.StatusConflict, "email_in_use", "E-Mail already in use")
+	problem.Register(user.ErrInvalidEmail, http.StatusBadRequest, "invalid_email", "Invalid email address")
+	problem.Register(user.ErrLastAdmin, http.StatusConflict, "last_admin", "Cannot remove the last admin")
+	problem.Register(user.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor", "Invalid cursor")
+}
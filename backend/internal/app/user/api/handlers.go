@@ -3,19 +3,14 @@ package userapi
 import (
 	"appliedTo/internal/app/user"
 	"appliedTo/internal/platform/http/middleware"
-	"errors"
+	"appliedTo/internal/platform/http/problem"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // ---- Response types used in Swagger ----
 
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
 type MessageResponse struct {
 	Message string `json:"message"`
 }
@@ -37,35 +32,154 @@ func NewHandlers(svc *user.Service) *UserHandlers {
 	return &UserHandlers{Svc: svc}
 }
 
+// GetMe godoc
+// @Summary      Get the current user
+// @Description  Returns the authenticated user.
+// @Tags         user
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  UserResponse           "Current user"
+// @Failure      401  {object}  problem.Problem        "Authentication required"
+// @Failure      404  {object}  problem.Problem        "User not found"
+// @Failure      500  {object}  problem.Problem        "Database query failed"
+// @Router       /user/me [get]
+func (h *UserHandlers) GetMe(c *gin.Context) {
+	id, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.Svc.GetByID(c.Request.Context(), id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, UserResponse{User: resp})
+}
+
+// PatchMe godoc
+// @Summary      Partially update the current user
+// @Description  Updates only the provided fields on the authenticated user. The role cannot be changed here.
+// @Tags         user
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      user.UserPatchDto  true  "Fields to patch"
+// @Success      200      {object}  MessageUserResponse    "User updated successfully"
+// @Failure      400      {object}  problem.Problem        "Invalid request payload or invalid field values"
+// @Failure      401      {object}  problem.Problem        "Authentication required"
+// @Failure      404      {object}  problem.Problem        "User not found"
+// @Failure      409      {object}  problem.Problem        "Email already in use"
+// @Failure      500      {object}  problem.Problem        "Could not update user"
+// @Router       /user/me [patch]
+func (h *UserHandlers) PatchMe(c *gin.Context) {
+	id, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	var dto user.UserPatchDto
+	if err := c.ShouldBindJSON(&dto); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	resp, err := h.Svc.Patch(c.Request.Context(), id, dto)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageUserResponse{
+		Message: "User updated successfully",
+		User:    resp,
+	})
+}
+
+// DeleteMe godoc
+// @Summary      Delete the current user
+// @Description  Removes the authenticated user and all of their data. The last admin cannot delete their account.
+// @Tags         user
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  MessageResponse        "User deleted successfully"
+// @Failure      401  {object}  problem.Problem        "Authentication required"
+// @Failure      404  {object}  problem.Problem        "User not found"
+// @Failure      409  {object}  problem.Problem        "Last admin"
+// @Failure      500  {object}  problem.Problem        "Could not delete user"
+// @Router       /user/me [delete]
+func (h *UserHandlers) DeleteMe(c *gin.Context) {
+	id, ok := middleware.RequireAuthUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.Svc.Delete(c.Request.Context(), id); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "User deleted successfully"})
+}
+
+// ListUsers godoc
+// @Summary      List users
+// @Description  Pages through all users by ID. Admins only.
+// @Tags         admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        q       query     string  false  "Matches first name, last name or email"
+// @Param        role    query     string  false  "Only users with this role"  Enums(user, admin)
+// @Param        limit   query     int     false  "Page size (max 200)"  default(50)
+// @Param        cursor  query     string  false  "Cursor from a previous page"
+// @Success      200     {object}  user.UserListDto  "Page of users"
+// @Failure      400     {object}  problem.Problem   "Invalid query"
+// @Failure      401     {object}  problem.Problem   "Authentication required"
+// @Failure      403     {object}  problem.Problem   "Admins only"
+// @Failure      500     {object}  problem.Problem   "Database query failed"
+// @Router       /admin/users [get]
+func (h *UserHandlers) ListUsers(c *gin.Context) {
+	var q user.UserListQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		problem.InvalidPayload(c)
+		return
+	}
+
+	out, err := h.Svc.List(c.Request.Context(), q)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
 // CreateUser godoc
 // @Summary      Create a new user
-// @Description  Creates a new user. Email must be unique; password is hashed.
-// @Tags         user
+// @Description  Creates a new user with the given role (default user). Email must be unique; password is hashed. Admins only.
+// @Tags         admin
+// @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        user  body      user.UserCreateDto  true  "User data"
+// @Param        user  body      user.UserAdminCreateDto  true  "User data"
 // @Success      200   {object}  MessageUserResponse     "User created successfully"
-// @Failure      400   {object}  ErrorResponse           "Invalid input"
-// @Failure      409   {object}  ErrorResponse           "Email already in use"
-// @Failure      500   {object}  ErrorResponse           "Could not create user"
-// @Router       /user [post]
+// @Failure      400   {object}  problem.Problem         "Invalid input"
+// @Failure      401   {object}  problem.Problem         "Authentication required"
+// @Failure      403   {object}  problem.Problem         "Admins only"
+// @Failure      409   {object}  problem.Problem         "Email already in use"
+// @Failure      500   {object}  problem.Problem         "Could not create user"
+// @Router       /admin/users [post]
 func (h *UserHandlers) CreateUser(c *gin.Context) {
-	var dto user.UserCreateDto
+	var dto user.UserAdminCreateDto
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		problem.InvalidPayload(c)
 		return
 	}
 
-	resp, _, err := h.Svc.Create(c.Request.Context(), dto)
+	resp, err := h.Svc.AdminCreate(c.Request.Context(), dto)
 	if err != nil {
-		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Email is invalid"})
-		case errors.Is(err, user.ErrEmailInUse):
-			c.JSON(http.StatusConflict, ErrorResponse{Error: "E-Mail is already in use"})
-		default:
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-		}
+		problem.Error(c, err)
 		return
 	}
 
@@ -77,26 +191,25 @@ func (h *UserHandlers) CreateUser(c *gin.Context) {
 
 // GetUser godoc
 // @Summary      Get a user by ID
-// @Description  Returns the user for the given ID.
-// @Tags         user
+// @Description  Returns the user for the given ID. Admins only.
+// @Tags         admin
+// @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "User ID"  example(123)
 // @Success      200  {object}  UserResponse           "Successfully retrieved user"
-// @Failure      400  {object}  ErrorResponse          "Invalid ID"
-// @Failure      404  {object}  ErrorResponse          "User not found"
-// @Failure      500  {object}  ErrorResponse          "Database query failed"
-// @Router       /user/{id} [get]
+// @Failure      400  {object}  problem.Problem        "Invalid ID"
+// @Failure      401  {object}  problem.Problem        "Authentication required"
+// @Failure      403  {object}  problem.Problem        "Admins only"
+// @Failure      404  {object}  problem.Problem        "User not found"
+// @Failure      500  {object}  problem.Problem        "Database query failed"
+// @Router       /admin/users/{id} [get]
 func (h *UserHandlers) GetUser(c *gin.Context) {
 	id := c.GetUint(middleware.CtxKeyUserID)
 
 	resp, err := h.Svc.GetByID(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Database query failed"})
+		problem.Error(c, err)
 		return
 	}
 
@@ -105,39 +218,33 @@ func (h *UserHandlers) GetUser(c *gin.Context) {
 
 // UpdateUser godoc
 // @Summary      Update a user (full replace)
-// @Description  Replaces all user fields with the provided payload.
-// @Tags         user
+// @Description  Replaces all user fields with the provided payload. The role is kept. Admins only.
+// @Tags         admin
+// @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        id    path      int                     true  "User ID"  example(123)
 // @Param        user  body      user.UserCreateDto  true  "User data"
 // @Success      200   {object}  MessageUserResponse     "User successfully modified."
-// @Failure      400   {object}  ErrorResponse           "Invalid input"
-// @Failure      404   {object}  ErrorResponse           "User not found"
-// @Failure      409   {object}  ErrorResponse           "Email already in use"
-// @Failure      500   {object}  ErrorResponse           "Could not update user"
-// @Router       /user/{id} [put]
+// @Failure      400   {object}  problem.Problem         "Invalid input"
+// @Failure      401   {object}  problem.Problem         "Authentication required"
+// @Failure      403   {object}  problem.Problem         "Admins only"
+// @Failure      404   {object}  problem.Problem         "User not found"
+// @Failure      409   {object}  problem.Problem         "Email already in use"
+// @Failure      500   {object}  problem.Problem         "Could not update user"
+// @Router       /admin/users/{id} [put]
 func (h *UserHandlers) UpdateUser(c *gin.Context) {
 	id := c.GetUint(middleware.CtxKeyUserID)
 
 	var dto user.UserCreateDto
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		problem.InvalidPayload(c)
 		return
 	}
 
 	resp, err := h.Svc.Update(c.Request.Context(), id, dto)
 	if err != nil {
-		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid email address"})
-		case errors.Is(err, user.ErrEmailInUse):
-			c.JSON(http.StatusConflict, ErrorResponse{Error: "Email already in use"})
-		case errors.Is(err, gorm.ErrRecordNotFound):
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
-		default:
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
-		}
+		problem.Error(c, err)
 		return
 	}
 
@@ -149,39 +256,33 @@ func (h *UserHandlers) UpdateUser(c *gin.Context) {
 
 // PatchUser godoc
 // @Summary      Partially update a user
-// @Description  Updates only the provided fields on the user with the given ID.
-// @Tags         user
+// @Description  Updates only the provided fields on the user with the given ID, including the role. The last admin cannot be demoted. Admins only.
+// @Tags         admin
+// @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        id       path      int                    true  "User ID"  example(123)
-// @Param        payload  body      user.UserPatchDto  true  "Fields to patch"
+// @Param        payload  body      user.UserAdminPatchDto  true  "Fields to patch"
 // @Success      200      {object}  MessageUserResponse    "User updated successfully"
-// @Failure      400      {object}  ErrorResponse          "Invalid request payload or invalid field values"
-// @Failure      404      {object}  ErrorResponse          "User not found"
-// @Failure      409      {object}  ErrorResponse          "Email already in use"
-// @Failure      500      {object}  ErrorResponse          "Could not update user"
-// @Router       /user/{id} [patch]
+// @Failure      400      {object}  problem.Problem        "Invalid request payload or invalid field values"
+// @Failure      401      {object}  problem.Problem        "Authentication required"
+// @Failure      403      {object}  problem.Problem        "Admins only"
+// @Failure      404      {object}  problem.Problem        "User not found"
+// @Failure      409      {object}  problem.Problem        "Email already in use or last admin"
+// @Failure      500      {object}  problem.Problem        "Could not update user"
+// @Router       /admin/users/{id} [patch]
 func (h *UserHandlers) PatchUser(c *gin.Context) {
 	id := c.GetUint(middleware.CtxKeyUserID)
 
-	var dto user.UserPatchDto
+	var dto user.UserAdminPatchDto
 	if err := c.ShouldBindJSON(&dto); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request payload"})
+		problem.InvalidPayload(c)
 		return
 	}
 
-	resp, err := h.Svc.Patch(c.Request.Context(), id, dto)
+	resp, err := h.Svc.AdminPatch(c.Request.Context(), id, dto)
 	if err != nil {
-		switch {
-		case errors.Is(err, user.ErrInvalidEmail):
-			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid email address"})
-		case errors.Is(err, user.ErrEmailInUse):
-			c.JSON(http.StatusConflict, ErrorResponse{Error: "Email already in use"})
-		case errors.Is(err, gorm.ErrRecordNotFound):
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
-		default:
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Could not update user"})
-		}
+		problem.Error(c, err)
 		return
 	}
 
@@ -193,24 +294,24 @@ func (h *UserHandlers) PatchUser(c *gin.Context) {
 
 // DeleteUser godoc
 // @Summary      Delete a user
-// @Description  Removes the user with the given ID.
-// @Tags         user
+// @Description  Removes the user with the given ID. The last admin cannot be deleted. Admins only.
+// @Tags         admin
+// @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "User ID"  example(123)
 // @Success      200  {object}  MessageResponse        "User deleted successfully"
-// @Failure      404  {object}  ErrorResponse          "User not found"
-// @Failure      500  {object}  ErrorResponse          "Could not delete user"
-// @Router       /user/{id} [delete]
+// @Failure      401  {object}  problem.Problem        "Authentication required"
+// @Failure      403  {object}  problem.Problem        "Admins only"
+// @Failure      404  {object}  problem.Problem        "User not found"
+// @Failure      409  {object}  problem.Problem        "Last admin"
+// @Failure      500  {object}  problem.Problem        "Could not delete user"
+// @Router       /admin/users/{id} [delete]
 func (h *UserHandlers) DeleteUser(c *gin.Context) {
 	id := c.GetUint(middleware.CtxKeyUserID)
 
 	if err := h.Svc.Delete(c.Request.Context(), id); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Could not delete user"})
+		problem.Error(c, err)
 		return
 	}
 
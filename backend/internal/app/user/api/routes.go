@@ -6,32 +6,30 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupUserRoutes(h *UserHandlers, requireID gin.HandlerFunc) routes.RouteConfig {
+func SetupUserRoutes(h *UserHandlers, requireAuth gin.HandlerFunc) routes.RouteConfig {
 	return routes.RouteConfig{
 		Prefix: "/user",
+		Use:    []gin.HandlerFunc{requireAuth},
 		Register: func(g *gin.RouterGroup) {
-			withID := g.Group("/:id", requireID)
-			withID.GET("", h.GetUser)
-			withID.PUT("", h.UpdateUser)
-			withID.PATCH("", h.PatchUser)
-			withID.DELETE("", h.DeleteUser)
+			g.GET("/me", h.GetMe)
+			g.PATCH("/me", h.PatchMe)
+			g.DELETE("/me", h.DeleteMe)
 		},
 	}
 }
 
-type AdminRouteOpts struct {
-	RequireAuth gin.HandlerFunc
-	RequireAdmin gin.HandlerFunc
-}
-
-func SetupAdminUserRoutes(h *UserHandlers, opts AdminRouteOpts) routes.RouteConfig {
+func SetupAdminUserRoutes(h *UserHandlers, opts routes.AdminRouteOpts) routes.RouteConfig {
 	return routes.RouteConfig{
 		Prefix: "/admin/users",
+		Use:    []gin.HandlerFunc{opts.RequireAuth, opts.RequireAdmin},
 		Register: func(g *gin.RouterGroup) {
-			admin := g.Group("", opts.RequireAuth, opts.RequireAdmin)
-
-			admin.POST("", h.CreateUser)
+			g.GET("", h.ListUsers)
+			g.POST("", h.CreateUser)
+			withID := g.Group("/:id", opts.RequireID)
+			withID.GET("", h.GetUser)
+			withID.PUT("", h.UpdateUser)
+			withID.PATCH("", h.PatchUser)
+			withID.DELETE("", h.DeleteUser)
 		},
 	}
-
 }
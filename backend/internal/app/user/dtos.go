@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 type BaseUserDto struct {
     FirstName string `json:"firstName"`
     LastName string `json:"lastName"`
@@ -19,5 +21,34 @@ type UserPatchDto struct {
 }
 
 type UserPublicDto struct {
+    ID uint `json:"id"`
     BaseUserDto
+    Role string `json:"role"`
+    EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"`
+}
+
+// UserAdminCreateDto is a user created by an admin. Role defaults to user.
+type UserAdminCreateDto struct {
+    UserCreateDto
+    Role string `json:"role,omitempty"`
+}
+
+// UserAdminPatchDto lets an admin change a user's role along with the
+// fields users may patch themselves.
+type UserAdminPatchDto struct {
+    UserPatchDto
+    Role *string `json:"role,omitempty"`
+}
+
+// UserListQuery pages through users by ID. Q matches names and email.
+type UserListQuery struct {
+    Q      string `form:"q"`
+    Role   string `form:"role"`
+    Limit  int    `form:"limit"`
+    Cursor string `form:"cursor"`
+}
+
+type UserListDto struct {
+    Items      []UserPublicDto `json:"items"`
+    NextCursor *string         `json:"nextCursor,omitempty"`
 }
@@ -26,11 +26,14 @@ func PatchModel(m *User, dto UserPatchDto) {
 
 func MapModelToPublicDto(u User) UserPublicDto {
 	return UserPublicDto{
+		ID: u.ID,
 		BaseUserDto: BaseUserDto{
 			FirstName: u.FirstName,
 			LastName:  u.LastName,
 			Email:     u.Email,
 		},
+		Role:            string(u.Role),
+		EmailVerifiedAt: u.EmailVerifiedAt,
 	}
 }
 
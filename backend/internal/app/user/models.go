@@ -8,6 +8,19 @@ type User struct {
     LastName string `json:"lastName"`
 	Email string `json:"email" gorm:"uniqueIndex;size:320"`
     Password string `json:"-"`
+    Role Role `json:"role" gorm:"type:VARCHAR(16);not null;default:user;index"`
+    // EmailVerifiedAt is set once the user proves they own Email and reset
+    // when Email changes.
+    EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"`
     Created time.Time `json:"created" gorm:"autoCreateTime"`
 }
 
+// Role decides which routes a user may reach. It is carried in the access
+// token, so a change takes effect with the next token.
+type Role string
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+var Roles = []Role{RoleUser, RoleAdmin}
@@ -3,14 +3,25 @@ package user
 import (
 	"appliedTo/internal/platform/validate"
 	"context"
+	"encoding/base64"
 	"errors"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
-	ErrEmailInUse   = errors.New("email already in use")
-	ErrInvalidEmail = errors.New("invalid email")
+	ErrEmailInUse    = errors.New("email already in use")
+	ErrInvalidEmail  = errors.New("invalid email")
+	ErrLastAdmin     = errors.New("cannot remove the last admin")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
 )
 
 type Service struct {
@@ -26,9 +37,32 @@ func NewService(db *gorm.DB, hasher interface {
 	return &Service{db: db, hasher: hasher}
 }
 
+// WithDB returns a copy of s that works on db, such as a transaction.
+func (s *Service) WithDB(db *gorm.DB) *Service {
+	return &Service{db: db, hasher: s.hasher}
+}
+
 // -------- CREATE --------
 
+// Create registers a user with RoleUser.
 func (s *Service) Create(ctx context.Context, dto UserCreateDto) (UserPublicDto, uint, error) {
+	return s.create(ctx, dto, RoleUser)
+}
+
+// AdminCreate creates a user with the given role.
+func (s *Service) AdminCreate(ctx context.Context, dto UserAdminCreateDto) (UserPublicDto, error) {
+	role := RoleUser
+	if dto.Role != "" {
+		role = Role(dto.Role)
+	}
+	if err := validateRole(role); err != nil {
+		return UserPublicDto{}, err
+	}
+	out, _, err := s.create(ctx, dto.UserCreateDto, role)
+	return out, err
+}
+
+func (s *Service) create(ctx context.Context, dto UserCreateDto, role Role) (UserPublicDto, uint, error) {
 	if err := validate.Required(
 		validate.Field{Name: "firstname", Value: dto.FirstName},
 		validate.Field{Name: "lastname", Value: dto.LastName},
@@ -56,6 +90,7 @@ func (s *Service) Create(ctx context.Context, dto UserCreateDto) (UserPublicDto,
 	user := CreateModel(dto)
 	user.Email = normalizedEmail
 	user.Password = hash
+	user.Role = role
 
 	if err := s.db.WithContext(ctx).Create(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
@@ -77,6 +112,52 @@ func (s *Service) GetByID(ctx context.Context, id uint) (UserPublicDto, error) {
 	return MapModelToPublicDto(user), nil
 }
 
+// List pages through all users by ID.
+func (s *Service) List(ctx context.Context, in UserListQuery) (UserListDto, error) {
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	q := s.db.WithContext(ctx).Model(&User{})
+	if in.Cursor != "" {
+		after, err := decodeCursor(in.Cursor)
+		if err != nil {
+			return UserListDto{}, err
+		}
+		q = q.Where("id > ?", after)
+	}
+	if in.Role != "" {
+		if err := validateRole(Role(in.Role)); err != nil {
+			return UserListDto{}, err
+		}
+		q = q.Where("role = ?", in.Role)
+	}
+	if term := strings.TrimSpace(in.Q); term != "" {
+		like := "%" + likeEscaper.Replace(term) + "%"
+		q = q.Where("(first_name ILIKE ? OR last_name ILIKE ? OR email ILIKE ?)", like, like, like)
+	}
+
+	var users []User
+	if err := q.Order("id").Limit(limit + 1).Find(&users).Error; err != nil {
+		return UserListDto{}, err
+	}
+
+	out := UserListDto{Items: make([]UserPublicDto, 0, min(len(users), limit))}
+	if len(users) > limit {
+		users = users[:limit]
+		next := encodeCursor(users[len(users)-1].ID)
+		out.NextCursor = &next
+	}
+	for _, u := range users {
+		out.Items = append(out.Items, MapModelToPublicDto(u))
+	}
+	return out, nil
+}
+
 // -------- UPDATE --------
 
 func (s *Service) Update(ctx context.Context, id uint, dto UserCreateDto) (UserPublicDto, error) {
@@ -99,7 +180,7 @@ func (s *Service) Update(ctx context.Context, id uint, dto UserCreateDto) (UserP
 		return UserPublicDto{}, ErrInvalidEmail
 	}
 
-	taken, err := s.emailTaken(ctx, normalizedEmail, user.ID)
+	taken, err := emailTaken(s.db.WithContext(ctx), normalizedEmail, user.ID)
 	if err != nil {
 		return UserPublicDto{}, err
 	}
@@ -112,6 +193,9 @@ func (s *Service) Update(ctx context.Context, id uint, dto UserCreateDto) (UserP
 		return UserPublicDto{}, err
 	}
 
+	if normalizedEmail != user.Email {
+		user.EmailVerifiedAt = nil
+	}
 	user.FirstName = dto.FirstName
 	user.LastName = dto.LastName
 	user.Email = normalizedEmail
@@ -126,57 +210,190 @@ func (s *Service) Update(ctx context.Context, id uint, dto UserCreateDto) (UserP
 
 // -------- PATCH --------
 
+// Patch applies the changes users may make to their own account.
 func (s *Service) Patch(ctx context.Context, id uint, dto UserPatchDto) (UserPublicDto, error) {
+	return s.patch(ctx, id, dto, nil)
+}
+
+// AdminPatch additionally changes the role. The last admin cannot be
+// demoted.
+func (s *Service) AdminPatch(ctx context.Context, id uint, dto UserAdminPatchDto) (UserPublicDto, error) {
+	if dto.Role == nil {
+		return s.patch(ctx, id, dto.UserPatchDto, nil)
+	}
+	role := Role(*dto.Role)
+	if err := validateRole(role); err != nil {
+		return UserPublicDto{}, err
+	}
+	return s.patch(ctx, id, dto.UserPatchDto, &role)
+}
+
+func (s *Service) patch(ctx context.Context, id uint, dto UserPatchDto, role *Role) (out UserPublicDto, err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		out, err = patchInTx(tx, id, dto, role)
+		return err
+	})
+	return out, err
+}
+
+// -------- DELETE --------
+
+// Delete removes a user. The last admin cannot be deleted.
+func (s *Service) Delete(ctx context.Context, id uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user User
+		if err := tx.Select("id", "role").First(&user, id).Error; err != nil {
+			return err
+		}
+		if user.Role == RoleAdmin {
+			if err := ensureOtherAdmin(tx, user.ID); err != nil {
+				return err
+			}
+		}
+		res := tx.Delete(&User{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		// GORM doesn't error when nothing is deleted; turn that into a 404 upstream if desired
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// -------- ROLES --------
+
+// BootstrapAdmin makes the user with the given email an admin unless some
+// user already is one, so that demoting the first admin later sticks. It
+// reports whether it promoted the user, and gorm.ErrRecordNotFound if there
+// is no such user.
+func (s *Service) BootstrapAdmin(ctx context.Context, email string) (bool, error) {
+	normalizedEmail, err := validate.NormalizeAndValidateEmail(email)
+	if err != nil {
+		return false, ErrInvalidEmail
+	}
+	res := s.db.WithContext(ctx).Model(&User{}).
+		Where("email = ?", normalizedEmail).
+		Where("NOT EXISTS (SELECT 1 FROM users WHERE role = ?)", RoleAdmin).
+		Update("role", RoleAdmin)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+	var n int64
+	if err := s.db.WithContext(ctx).Model(&User{}).Where("email = ?", normalizedEmail).Count(&n).Error; err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, gorm.ErrRecordNotFound
+	}
+	return false, nil
+}
+
+// HasAdmin reports whether any user is an admin.
+func (s *Service) HasAdmin(ctx context.Context) (bool, error) {
+	var n int64
+	err := s.db.WithContext(ctx).Model(&User{}).Where("role = ?", RoleAdmin).Limit(1).Count(&n).Error
+	return n > 0, err
+}
+
+// -------- helpers --------
+
+func patchInTx(tx *gorm.DB, id uint, dto UserPatchDto, role *Role) (UserPublicDto, error) {
 	var user User
-	if err := s.db.WithContext(ctx).First(&user, id).Error; err != nil {
+	if err := tx.First(&user, id).Error; err != nil {
 		return UserPublicDto{}, err
 	}
 
+	current := user.Email
+	var email *string
 	if dto.Email != nil {
 		norm, err := validate.NormalizeAndValidateEmail(*dto.Email)
 		if err != nil {
 			return UserPublicDto{}, ErrInvalidEmail
 		}
 		if norm != user.Email {
-			taken, err := s.emailTaken(ctx, norm, user.ID)
+			taken, err := emailTaken(tx, norm, user.ID)
 			if err != nil {
 				return UserPublicDto{}, err
 			}
 			if taken {
 				return UserPublicDto{}, ErrEmailInUse
 			}
-			user.Email = norm
 		}
+		email = &norm
 	}
 
 	PatchModel(&user, dto)
+	if email != nil {
+		if *email != current {
+			user.EmailVerifiedAt = nil
+		}
+		user.Email = *email
+	}
+	if role != nil && *role != user.Role {
+		if user.Role == RoleAdmin {
+			if err := ensureOtherAdmin(tx, user.ID); err != nil {
+				return UserPublicDto{}, err
+			}
+		}
+		user.Role = *role
+	}
 
-	if err := s.db.WithContext(ctx).Save(&user).Error; err != nil {
+	if err := tx.Save(&user).Error; err != nil {
 		return UserPublicDto{}, err
 	}
 
 	return MapModelToPublicDto(user), nil
 }
 
-// -------- DELETE --------
-
-func (s *Service) Delete(ctx context.Context, id uint) error {
-	tx := s.db.WithContext(ctx).Delete(&User{}, id)
-	if tx.Error != nil {
-		return tx.Error
+// ensureOtherAdmin locks the admins and fails with ErrLastAdmin unless one
+// besides id remains.
+func ensureOtherAdmin(tx *gorm.DB, id uint) error {
+	var others []User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("role = ? AND id <> ?", RoleAdmin, id).
+		Limit(1).
+		Find(&others).Error; err != nil {
+		return err
 	}
-	// GORM doesn't error when nothing is deleted; turn that into a 404 upstream if desired
-	if tx.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+	if len(others) == 0 {
+		return ErrLastAdmin
 	}
 	return nil
 }
 
-// -------- helpers --------
+func validateRole(role Role) error {
+	var errs validate.Errors
+	validate.OneOf(&errs, "role", role, Roles...)
+	return errs.Err()
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func encodeCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeCursor(raw string) (uint, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil || id == 0 || uint64(uint(id)) != id {
+		return 0, ErrInvalidCursor
+	}
+	return uint(id), nil
+}
 
-func (s *Service) emailTaken(ctx context.Context, normalizedEmail string, excludeID uint) (bool, error) {
+func emailTaken(db *gorm.DB, normalizedEmail string, excludeID uint) (bool, error) {
 	var count int64
-	q := s.db.WithContext(ctx).Model(&User{}).Where("email = ?", normalizedEmail)
+	q := db.Model(&User{}).Where("email = ?", normalizedEmail)
 	if excludeID != 0 {
 		q = q.Where("id <> ?", excludeID)
 	}
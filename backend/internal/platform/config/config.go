@@ -8,25 +8,99 @@ import (
 )
 
 type Config struct {
-	AppPort      string
-	DBHost       string
-	DBPort       string
-	DBUser       string
-	DBPassword   string
-	DBName       string
-	DBSSLMode    string
-	DBTimeZone   string
-	BcryptCost   int
-	DBMaxOpen    int
-	DBMaxIdle    int
-	DBMaxLife    time.Duration
-	JWTSecret    string
-	JWTIssuer    string
-	JWTAccessTTL time.Duration
+	AppPort    string
+	DBHost     string
+	DBPort     string
+	DBUser     string
+	DBPassword string
+	DBName     string
+	DBSSLMode  string
+	DBTimeZone string
+	BcryptCost int
+	// PasswordHasher selects how new passwords are hashed: "bcrypt" or
+	// "argon2id". Either verifies the other's hashes and replaces them on
+	// the next login, so switching back and forth locks nobody out.
+	PasswordHasher    string
+	Argon2Memory      uint32
+	Argon2Time        uint32
+	Argon2Parallelism uint8
+	DBMaxOpen         int
+	DBMaxIdle         int
+	DBMaxLife         time.Duration
+	// DBMigrateOnStart applies pending migrations on boot instead of
+	// refusing to start.
+	DBMigrateOnStart bool
+	JWTSecret        string
+	JWTIssuer        string
+	JWTAccessTTL     time.Duration
+	JWTRefreshTTL    time.Duration
+
+	// Follow-up reminders
+	RemindersEnabled  bool
+	ReminderInterval  time.Duration
+	ReminderLookback  time.Duration
+	ReminderBatchSize int
+	// Notifier selects how reminders and account mail are delivered: "log"
+	// or "smtp". The log redacts the tokens of account mail, so email
+	// verification and password reset need smtp.
+	Notifier     string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	SMTPTimeout  time.Duration
+
+	// Document storage
+	// StorageBackend selects where uploads are kept: "local" or "s3".
+	StorageBackend    string
+	StorageDir        string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool
+	DocumentMaxBytes  int64
+
+	// Salary normalization
+	SalaryBaseCurrency string
+	SalaryHoursPerWeek float64
+	// ExchangeRatesFile, if set, replaces the exchange rates on start.
+	ExchangeRatesFile string
+
+	// Account mail
+	// AppURL is the address of the web app that verification and password
+	// reset links point to.
+	AppURL                   string
+	RequireEmailVerification bool
+	EmailVerificationTTL     time.Duration
+	PasswordResetTTL         time.Duration
+
+	// Login throttling
+	// LoginThrottleStore selects where failed logins are counted:
+	// "postgres", shared by all replicas, or "memory".
+	LoginThrottleStore string
+	// LoginMaxFailures and LoginIPMaxFailures are the failed logins after
+	// which an account or a client IP is locked out for LoginLockout.
+	LoginMaxFailures   int
+	LoginIPMaxFailures int
+	LoginLockout       time.Duration
+	// TrustedProxies are the addresses or CIDRs whose X-Forwarded-For header
+	// is believed when determining the client IP. Empty trusts none.
+	TrustedProxies []string
 
 	// Non structural
+	// EnableSelfSignup lets anyone register; otherwise registration needs
+	// an invite, which lives for InviteTTL unless given an expiry.
 	EnableSelfSignup bool
+	InviteTTL        time.Duration
+	// EnableAdminApi mounts the /admin routes, which require the admin role.
 	EnableAdminApi bool
+	// AdminEmail, if set, bootstraps the first admin: while no user is an
+	// admin, this user is made one on start, or may register without an
+	// invite and becomes one.
+	AdminEmail string
 }
 
 func Load() Config {
@@ -41,11 +115,41 @@ func Load() Config {
 	v.SetDefault("DB_SSLMODE", "disable")
 	v.SetDefault("DB_TIMEZONE", "UTC")
 	v.SetDefault("BCRYPT_COST", 12)
+	v.SetDefault("PASSWORD_HASHER", "bcrypt")
+	v.SetDefault("ARGON2_MEMORY", 64*1024)
+	v.SetDefault("ARGON2_TIME", 3)
+	v.SetDefault("ARGON2_PARALLELISM", 4)
 	v.SetDefault("DB_MAXOPEN", 20)
 	v.SetDefault("DB_MAXIDLE", 10)
 	v.SetDefault("DB_MAXLIFE", "1h")
+	v.SetDefault("DB_MIGRATE_ON_START", false)
 	v.SetDefault("JWT_ISSUER", "appliedTo")
-	v.SetDefault("JWT_ACCESS_TTL", "24h")
+	v.SetDefault("JWT_ACCESS_TTL", "15m")
+	v.SetDefault("JWT_REFRESH_TTL", "720h")
+	v.SetDefault("REMINDERS_ENABLED", true)
+	v.SetDefault("REMINDER_INTERVAL", "1m")
+	v.SetDefault("REMINDER_LOOKBACK", "168h")
+	v.SetDefault("REMINDER_BATCH_SIZE", 50)
+	v.SetDefault("NOTIFIER", "log")
+	v.SetDefault("SMTP_PORT", "587")
+	v.SetDefault("SMTP_TIMEOUT", "30s")
+	v.SetDefault("STORAGE_BACKEND", "local")
+	v.SetDefault("STORAGE_DIR", "data/documents")
+	v.SetDefault("S3_REGION", "us-east-1")
+	v.SetDefault("S3_PATH_STYLE", true)
+	v.SetDefault("DOCUMENT_MAX_BYTES", 10<<20)
+	v.SetDefault("SALARY_BASE_CURRENCY", "USD")
+	v.SetDefault("SALARY_HOURS_PER_WEEK", 40)
+	v.SetDefault("REQUIRE_EMAIL_VERIFICATION", false)
+	v.SetDefault("EMAIL_VERIFICATION_TTL", "48h")
+	v.SetDefault("PASSWORD_RESET_TTL", "1h")
+	v.SetDefault("LOGIN_THROTTLE_STORE", "postgres")
+	v.SetDefault("LOGIN_MAX_FAILURES", 10)
+	v.SetDefault("LOGIN_IP_MAX_FAILURES", 50)
+	v.SetDefault("LOGIN_LOCKOUT", "15m")
+	v.SetDefault("ENABLE_SELF_SIGNUP", true)
+	v.SetDefault("INVITE_TTL", "168h")
+	v.SetDefault("ENABLE_ADMIN_API", false)
 
 	dur, err := time.ParseDuration(v.GetString("DB_MAXLIFE"))
 	if err != nil {
@@ -53,22 +157,85 @@ func Load() Config {
 	}
 
 	ttl, _ := time.ParseDuration(v.GetString("JWT_ACCESS_TTL"))
+	refreshTTL, _ := time.ParseDuration(v.GetString("JWT_REFRESH_TTL"))
+	reminderInterval, _ := time.ParseDuration(v.GetString("REMINDER_INTERVAL"))
+	reminderLookback, _ := time.ParseDuration(v.GetString("REMINDER_LOOKBACK"))
+	smtpTimeout, _ := time.ParseDuration(v.GetString("SMTP_TIMEOUT"))
+	inviteTTL, _ := time.ParseDuration(v.GetString("INVITE_TTL"))
+	verifyTTL, _ := time.ParseDuration(v.GetString("EMAIL_VERIFICATION_TTL"))
+	resetTTL, _ := time.ParseDuration(v.GetString("PASSWORD_RESET_TTL"))
+	loginLockout, _ := time.ParseDuration(v.GetString("LOGIN_LOCKOUT"))
+
+	var trustedProxies []string
+	for _, p := range strings.Split(v.GetString("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			trustedProxies = append(trustedProxies, p)
+		}
+	}
 
 	return Config{
-		AppPort:      v.GetString("APP_PORT"),
-		DBHost:       v.GetString("DB_HOST"),
-		DBPort:       v.GetString("DB_PORT"),
-		DBUser:       v.GetString("DB_USER"),
-		DBPassword:   v.GetString("DB_PASSWORD"),
-		DBName:       v.GetString("DB_NAME"),
-		DBSSLMode:    v.GetString("DB_SSLMODE"),
-		DBTimeZone:   v.GetString("DB_TIMEZONE"),
-		BcryptCost:   v.GetInt("BCRYPT_COST"),
-		DBMaxOpen:    v.GetInt("DB_MAXOPEN"),
-		DBMaxIdle:    v.GetInt("DB_MAXIDLE"),
-		DBMaxLife:    dur,
-		JWTSecret:    v.GetString("JWT_SECRET"),
-		JWTIssuer:    v.GetString("JWT_ISSUER"),
-		JWTAccessTTL: ttl,
+		AppPort:           v.GetString("APP_PORT"),
+		DBHost:            v.GetString("DB_HOST"),
+		DBPort:            v.GetString("DB_PORT"),
+		DBUser:            v.GetString("DB_USER"),
+		DBPassword:        v.GetString("DB_PASSWORD"),
+		DBName:            v.GetString("DB_NAME"),
+		DBSSLMode:         v.GetString("DB_SSLMODE"),
+		DBTimeZone:        v.GetString("DB_TIMEZONE"),
+		BcryptCost:        v.GetInt("BCRYPT_COST"),
+		PasswordHasher:    strings.ToLower(v.GetString("PASSWORD_HASHER")),
+		Argon2Memory:      v.GetUint32("ARGON2_MEMORY"),
+		Argon2Time:        v.GetUint32("ARGON2_TIME"),
+		Argon2Parallelism: uint8(v.GetUint("ARGON2_PARALLELISM")),
+		DBMaxOpen:         v.GetInt("DB_MAXOPEN"),
+		DBMaxIdle:         v.GetInt("DB_MAXIDLE"),
+		DBMaxLife:         dur,
+		DBMigrateOnStart:  v.GetBool("DB_MIGRATE_ON_START"),
+		JWTSecret:         v.GetString("JWT_SECRET"),
+		JWTIssuer:         v.GetString("JWT_ISSUER"),
+		JWTAccessTTL:      ttl,
+		JWTRefreshTTL:     refreshTTL,
+
+		RemindersEnabled:  v.GetBool("REMINDERS_ENABLED"),
+		ReminderInterval:  reminderInterval,
+		ReminderLookback:  reminderLookback,
+		ReminderBatchSize: v.GetInt("REMINDER_BATCH_SIZE"),
+		Notifier:          strings.ToLower(v.GetString("NOTIFIER")),
+		SMTPHost:          v.GetString("SMTP_HOST"),
+		SMTPPort:          v.GetString("SMTP_PORT"),
+		SMTPUsername:      v.GetString("SMTP_USERNAME"),
+		SMTPPassword:      v.GetString("SMTP_PASSWORD"),
+		SMTPFrom:          v.GetString("SMTP_FROM"),
+		SMTPTimeout:       smtpTimeout,
+
+		StorageBackend:    strings.ToLower(v.GetString("STORAGE_BACKEND")),
+		StorageDir:        v.GetString("STORAGE_DIR"),
+		S3Endpoint:        v.GetString("S3_ENDPOINT"),
+		S3Region:          v.GetString("S3_REGION"),
+		S3Bucket:          v.GetString("S3_BUCKET"),
+		S3AccessKeyID:     v.GetString("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: v.GetString("S3_SECRET_ACCESS_KEY"),
+		S3PathStyle:       v.GetBool("S3_PATH_STYLE"),
+		DocumentMaxBytes:  v.GetInt64("DOCUMENT_MAX_BYTES"),
+
+		SalaryBaseCurrency: strings.ToUpper(v.GetString("SALARY_BASE_CURRENCY")),
+		SalaryHoursPerWeek: v.GetFloat64("SALARY_HOURS_PER_WEEK"),
+		ExchangeRatesFile:  v.GetString("EXCHANGE_RATES_FILE"),
+
+		AppURL:                   v.GetString("APP_URL"),
+		RequireEmailVerification: v.GetBool("REQUIRE_EMAIL_VERIFICATION"),
+		EmailVerificationTTL:     verifyTTL,
+		PasswordResetTTL:         resetTTL,
+
+		LoginThrottleStore: strings.ToLower(v.GetString("LOGIN_THROTTLE_STORE")),
+		LoginMaxFailures:   v.GetInt("LOGIN_MAX_FAILURES"),
+		LoginIPMaxFailures: v.GetInt("LOGIN_IP_MAX_FAILURES"),
+		LoginLockout:       loginLockout,
+		TrustedProxies:     trustedProxies,
+
+		EnableSelfSignup: v.GetBool("ENABLE_SELF_SIGNUP"),
+		InviteTTL:        inviteTTL,
+		EnableAdminApi:   v.GetBool("ENABLE_ADMIN_API"),
+		AdminEmail:       v.GetString("ADMIN_EMAIL"),
 	}
 }
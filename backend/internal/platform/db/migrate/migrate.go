@@ -0,0 +1,327 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockKey identifies the session-level advisory lock held while migrating so
+// that replicas starting at the same time apply migrations one at a time.
+const lockKey int64 = 0x6170706c69656454 // "appliedT"
+
+var (
+	fileRe    = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	nonNameRe = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+var ErrNoMigrations = errors.New("no migrations found")
+
+// Func is a step of a migration written in Go, for data changes SQL cannot
+// express.
+type Func func(ctx context.Context, tx *sql.Tx) error
+
+// Migration is one versioned schema change with its up and down SQL.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// UpFunc, if set, runs after Up in the same transaction.
+	UpFunc Func
+}
+
+func (m Migration) String() string { return fmt.Sprintf("%04d_%s", m.Version, m.Name) }
+
+// Status is a migration together with the time it was applied, if it was.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load reads <version>_<name>.up.sql / .down.sql pairs from the root of fsys,
+// ordered by version. Every version needs both files.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	type pair struct {
+		Migration
+		hasUp, hasDown bool
+	}
+	byVersion := map[int64]*pair{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		parts := fileRe.FindStringSubmatch(e.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("migration %s: name must match <version>_<name>.(up|down).sql", e.Name())
+		}
+		version, _ := strconv.ParseInt(parts[1], 10, 64)
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		p := byVersion[version]
+		if p == nil {
+			p = &pair{Migration: Migration{Version: version, Name: parts[2]}}
+			byVersion[version] = p
+		}
+		if p.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, p.Name, parts[2])
+		}
+		if parts[3] == "up" {
+			p.Up, p.hasUp = string(body), true
+		} else {
+			p.Down, p.hasDown = string(body), true
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, p := range byVersion {
+		if !p.hasUp || !p.hasDown {
+			return nil, fmt.Errorf("migration %s: needs both an up and a down file", p.Migration)
+		}
+		out = append(out, p.Migration)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// WithFuncs sets the UpFunc of the migrations with the versions in funcs.
+func WithFuncs(ms []Migration, funcs map[int64]Func) ([]Migration, error) {
+	out := append([]Migration(nil), ms...)
+	for version, fn := range funcs {
+		i := sort.Search(len(out), func(i int) bool { return out[i].Version >= version })
+		if i == len(out) || out[i].Version != version {
+			return nil, fmt.Errorf("migration %d: Go step without SQL files", version)
+		}
+		out[i].UpFunc = fn
+	}
+	return out, nil
+}
+
+// Migrator applies and rolls back migrations, recording them in the
+// schema_migrations table.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Status lists every known migration and when it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Status, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		st := Status{Migration: mg}
+		if at, ok := applied[mg.Version]; ok {
+			st.AppliedAt = &at
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// Pending lists the migrations that have not been applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Migration
+	for _, st := range status {
+		if st.AppliedAt == nil {
+			out = append(out, st.Migration)
+		}
+	}
+	return out, nil
+}
+
+// Up applies all pending migrations in order, each in its own transaction.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mg := range m.migrations {
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mg.Up); err != nil {
+					return err
+				}
+				if mg.UpFunc != nil {
+					if err := mg.UpFunc(ctx, tx); err != nil {
+						return err
+					}
+				}
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mg.Version, mg.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("apply %s: %w", mg, err)
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the given number of most recently applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mg := m.migrations[i]
+			if _, ok := applied[mg.Version]; !ok {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if strings.TrimSpace(mg.Down) != "" {
+					if _, err := tx.ExecContext(ctx, mg.Down); err != nil {
+						return err
+					}
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mg.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("roll back %s: %w", mg, err)
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Create writes an empty up/down pair for the next version into dir and
+// returns the paths of both files.
+func Create(dir, name string, existing []Migration) (string, string, error) {
+	name = strings.Trim(nonNameRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("migration name required")
+	}
+	var version int64 = 1
+	if n := len(existing); n > 0 {
+		version = existing[n-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%04d_%s", version, name))
+	up, down := base+".up.sql", base+".down.sql"
+	for _, p := range []string{up, down} {
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := f.WriteString("-- " + filepath.Base(p) + "\n"); err != nil {
+			f.Close()
+			return "", "", err
+		}
+		if err := f.Close(); err != nil {
+			return "", "", err
+		}
+	}
+	return up, down, nil
+}
+
+// ---- helpers ----
+
+type execQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, db execQuerier) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context, db execQuerier) (map[int64]time.Time, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[int64]time.Time{}
+	for rows.Next() {
+		var v int64
+		var at time.Time
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, err
+		}
+		out[v] = at
+	}
+	return out, rows.Err()
+}
+
+// locked runs fn on a single connection holding the migration advisory lock.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	if len(m.migrations) == 0 {
+		return ErrNoMigrations
+	}
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, lockKey)
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS status_changes;
+DROP TABLE IF EXISTS job_applications;
+DROP TABLE IF EXISTS users;
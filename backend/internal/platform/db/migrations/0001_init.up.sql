@@ -0,0 +1,80 @@
+-- Baseline schema. Everything is IF NOT EXISTS so that databases created by
+-- the former AutoMigrate-on-boot are adopted as-is.
+
+CREATE TABLE IF NOT EXISTS users (
+    id         BIGSERIAL PRIMARY KEY,
+    first_name TEXT,
+    last_name  TEXT,
+    email      VARCHAR(320),
+    password   TEXT,
+    created    TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
+CREATE TABLE IF NOT EXISTS job_applications (
+    id                                 BIGSERIAL PRIMARY KEY,
+    created_at                         TIMESTAMPTZ,
+    updated_at                         TIMESTAMPTZ,
+    deleted_at                         TIMESTAMPTZ,
+    user_id                            BIGINT,
+    company                            TEXT,
+    title                              TEXT,
+    description                        TEXT,
+    status                             VARCHAR(24),
+    source                             VARCHAR(24),
+    applied_at                         TIMESTAMPTZ,
+    next_follow_up_at                  TIMESTAMPTZ,
+    last_contact_at                    TIMESTAMPTZ,
+    posting_url                        TEXT,
+    company_url                        TEXT,
+    contact_name                       TEXT,
+    contact_email                      TEXT,
+    external_job_id                    TEXT,
+    employment_type                    TEXT,
+    employment_duration                TEXT,
+    employment_work_location           TEXT,
+    employment_seniority               TEXT,
+    employment_hours_per_week          BIGINT,
+    employment_salary_range_from       BIGINT,
+    employment_salary_range_to         BIGINT,
+    employment_salary_range_currency   TEXT,
+    employment_salary_range_period     TEXT,
+    employment_salary_range_negotiable BOOLEAN,
+    location                           TEXT,
+    tags                               JSONB DEFAULT '[]'
+);
+CREATE INDEX IF NOT EXISTS idx_job_applications_user_id ON job_applications (user_id);
+CREATE INDEX IF NOT EXISTS idx_job_applications_deleted_at ON job_applications (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_job_applications_status ON job_applications (status);
+CREATE INDEX IF NOT EXISTS idx_job_applications_source ON job_applications (source);
+CREATE INDEX IF NOT EXISTS idx_job_applications_applied_at ON job_applications (applied_at);
+CREATE INDEX IF NOT EXISTS idx_job_applications_next_follow_up_at ON job_applications (next_follow_up_at);
+CREATE INDEX IF NOT EXISTS idx_job_applications_last_contact_at ON job_applications (last_contact_at);
+CREATE INDEX IF NOT EXISTS idx_job_applications_external_job_id ON job_applications (external_job_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_user_extid_src ON job_applications (user_id, source, external_job_id);
+
+-- Full-text search. Weights rank hits in company and title above contact and
+-- location, and those above the description. jobapplication.List queries
+-- this column with the same 'english' configuration.
+ALTER TABLE job_applications ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(company, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(contact_name, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(location, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'C')
+    ) STORED;
+CREATE INDEX IF NOT EXISTS idx_job_applications_search_vector
+    ON job_applications USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS status_changes (
+    id                 BIGSERIAL PRIMARY KEY,
+    job_application_id BIGINT NOT NULL,
+    from_status        VARCHAR(24),
+    to_status          VARCHAR(24) NOT NULL,
+    changed_by_user_id BIGINT,
+    changed_at         TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_status_changes_job_application_id ON status_changes (job_application_id);
+CREATE INDEX IF NOT EXISTS idx_status_changes_changed_by_user_id ON status_changes (changed_by_user_id);
+CREATE INDEX IF NOT EXISTS idx_status_changes_changed_at ON status_changes (changed_at);
@@ -0,0 +1 @@
+-- Nothing to restore: the dropped tables never held data.
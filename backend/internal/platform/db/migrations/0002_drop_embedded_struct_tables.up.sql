@@ -0,0 +1,5 @@
+-- AutoMigrate used to migrate the embedded Employment and SalaryRange structs
+-- as standalone tables. Their columns live on job_applications; the tables
+-- were never written to.
+DROP TABLE IF EXISTS employments;
+DROP TABLE IF EXISTS salary_ranges;
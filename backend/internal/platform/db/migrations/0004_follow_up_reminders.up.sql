@@ -0,0 +1,15 @@
+-- One row per follow-up that fell due. The unique key makes enqueueing
+-- idempotent across scheduler replicas; state tracks delivery.
+CREATE TABLE follow_up_reminders (
+    id                 BIGSERIAL PRIMARY KEY,
+    job_application_id BIGINT NOT NULL REFERENCES job_applications (id) ON DELETE CASCADE,
+    due_at             TIMESTAMPTZ NOT NULL,
+    state              VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts           INT NOT NULL DEFAULT 0,
+    claimed_at         TIMESTAMPTZ,
+    sent_at            TIMESTAMPTZ,
+    last_error         TEXT,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uniq_follow_up_reminders_due UNIQUE (job_application_id, due_at)
+);
+CREATE INDEX idx_follow_up_reminders_state ON follow_up_reminders (state, due_at);
@@ -0,0 +1,15 @@
+CREATE TABLE interviews (
+    id                 BIGSERIAL PRIMARY KEY,
+    job_application_id BIGINT NOT NULL REFERENCES job_applications (id) ON DELETE CASCADE,
+    type               VARCHAR(16) NOT NULL,
+    scheduled_at       TIMESTAMPTZ NOT NULL,
+    duration_minutes   INT NOT NULL DEFAULT 60,
+    interviewers       JSONB NOT NULL DEFAULT '[]',
+    location           TEXT,
+    meeting_url        TEXT,
+    outcome            VARCHAR(16) NOT NULL DEFAULT 'Pending',
+    notes              TEXT,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_interviews_job_application_id ON interviews (job_application_id, scheduled_at);
@@ -0,0 +1,68 @@
+CREATE TABLE companies (
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name            TEXT NOT NULL,
+    normalized_name TEXT NOT NULL,
+    domain          TEXT,
+    industry        TEXT,
+    size            VARCHAR(16),
+    notes           TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uniq_companies_user_name UNIQUE (user_id, normalized_name)
+);
+CREATE INDEX idx_companies_domain ON companies (user_id, domain);
+
+ALTER TABLE job_applications
+    ADD COLUMN company_id BIGINT REFERENCES companies (id) ON DELETE SET NULL;
+CREATE INDEX idx_job_applications_company_id ON job_applications (company_id);
+
+-- Merge the free-text companies of existing applications. Names normalize
+-- like company.Normalize: lower case, runs of non-alphanumerics collapsed to a
+-- space, trailing legal-form suffixes dropped unless nothing else is left.
+CREATE TEMPORARY TABLE company_keys ON COMMIT DROP AS
+SELECT id AS job_application_id, user_id, name, norm, norm AS name_norm, domain
+FROM (
+    SELECT ja.id, ja.user_id, btrim(ja.company) AS name, base,
+           COALESCE(NULLIF(btrim(regexp_replace(base,
+               '( (inc|incorporated|llc|ltd|limited|gmbh|ag|corp|corporation|co|company|plc|sa|bv|srl|kg))+$', '')), ''),
+               base) AS norm,
+           NULLIF(regexp_replace(
+               substring(lower(btrim(ja.company_url)) FROM '^(?:[a-z][a-z0-9+.-]*://)?([^/:?#]+)'),
+               '^www\.', ''), '') AS domain
+    FROM job_applications ja,
+         LATERAL (SELECT btrim(regexp_replace(lower(ja.company), '[^[:alnum:]]+', ' ', 'g')) AS base) b
+) k
+WHERE norm <> '';
+
+-- Job boards and other shared hosts (company.sharedHosts) say nothing about
+-- the company.
+UPDATE company_keys SET domain = NULL
+WHERE domain ~ '(^|\.)(linkedin\.com|xing\.com|indeed\.com|glassdoor\.com|stepstone\.de|wellfound\.com|angel\.co|github\.com|google\.com|greenhouse\.io|lever\.co|myworkdayjobs\.com|workday\.com|smartrecruiters\.com|ashbyhq\.com|workable\.com|recruitee\.com|personio\.de|personio\.com|teamtailor\.com|jobvite\.com|icims\.com|bamboohr\.com|breezy\.hr|join\.com)$';
+
+-- Names that share a domain are the same company if the words of one start
+-- the other ("acme" and "acme robotics"); adopt the shortest such name key.
+UPDATE company_keys k SET norm = d.norm
+FROM (
+    SELECT a.job_application_id, min(o.norm) AS norm
+    FROM company_keys a
+    JOIN company_keys o ON o.user_id = a.user_id AND o.domain = a.domain
+                       AND (o.norm = a.norm OR a.norm LIKE o.norm || ' %')
+    GROUP BY a.job_application_id
+) d
+WHERE k.job_application_id = d.job_application_id;
+
+INSERT INTO companies (user_id, name, normalized_name, domain)
+SELECT user_id,
+       mode() WITHIN GROUP (ORDER BY name) FILTER (WHERE name_norm = norm),
+       norm,
+       mode() WITHIN GROUP (ORDER BY domain)
+FROM company_keys
+GROUP BY user_id, norm;
+
+-- Applications merged only by domain keep the name they gave.
+UPDATE job_applications ja SET company_id = c.id,
+    company = CASE WHEN k.name_norm = c.normalized_name THEN c.name ELSE k.name END
+FROM company_keys k
+JOIN companies c ON c.user_id = k.user_id AND c.normalized_name = k.norm
+WHERE ja.id = k.job_application_id;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS company_contacts;
+DROP TABLE IF EXISTS job_application_contacts;
+DROP TABLE IF EXISTS contacts;
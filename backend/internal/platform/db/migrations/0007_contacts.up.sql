@@ -0,0 +1,32 @@
+CREATE TABLE contacts (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    role         TEXT,
+    email        TEXT,
+    phone        TEXT,
+    linkedin_url TEXT,
+    notes        TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX uniq_contacts_user_email ON contacts (user_id, email) WHERE email IS NOT NULL;
+CREATE INDEX idx_contacts_user_name ON contacts (user_id, lower(name));
+
+CREATE TABLE job_application_contacts (
+    job_application_id BIGINT NOT NULL REFERENCES job_applications (id) ON DELETE CASCADE,
+    contact_id         BIGINT NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    PRIMARY KEY (job_application_id, contact_id)
+);
+CREATE INDEX idx_job_application_contacts_contact_id ON job_application_contacts (contact_id);
+
+CREATE TABLE company_contacts (
+    company_id BIGINT NOT NULL REFERENCES companies (id) ON DELETE CASCADE,
+    contact_id BIGINT NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+    PRIMARY KEY (company_id, contact_id)
+);
+CREATE INDEX idx_company_contacts_contact_id ON company_contacts (contact_id);
+
+-- Existing contact_name/contact_email values are turned into contacts by the
+-- Go step of this migration (see contacts.go), which normalizes addresses
+-- like the application does.
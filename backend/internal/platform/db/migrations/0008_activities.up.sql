@@ -0,0 +1,10 @@
+CREATE TABLE activities (
+    id                 BIGSERIAL PRIMARY KEY,
+    job_application_id BIGINT NOT NULL REFERENCES job_applications (id) ON DELETE CASCADE,
+    type               VARCHAR(24) NOT NULL,
+    occurred_at        TIMESTAMPTZ NOT NULL,
+    body               TEXT,
+    created_by_user_id BIGINT NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_activities_job_application_id ON activities (job_application_id, occurred_at DESC, id DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS job_application_documents;
+DROP TABLE IF EXISTS documents;
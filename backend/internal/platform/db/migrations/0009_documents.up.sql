@@ -0,0 +1,21 @@
+CREATE TABLE documents (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    filename     TEXT NOT NULL,
+    kind         VARCHAR(24) NOT NULL,
+    content_type TEXT NOT NULL,
+    size         BIGINT NOT NULL,
+    sha256       CHAR(64) NOT NULL,
+    storage_key  TEXT NOT NULL,
+    uploaded_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- A file is stored once per user; uploading it again reuses the document.
+CREATE UNIQUE INDEX uniq_documents_user_sha256 ON documents (user_id, sha256);
+
+CREATE TABLE job_application_documents (
+    job_application_id BIGINT NOT NULL REFERENCES job_applications (id) ON DELETE CASCADE,
+    document_id        BIGINT NOT NULL REFERENCES documents (id) ON DELETE CASCADE,
+    linked_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (job_application_id, document_id)
+);
+CREATE INDEX idx_job_application_documents_document_id ON job_application_documents (document_id);
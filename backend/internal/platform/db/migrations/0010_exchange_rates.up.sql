@@ -0,0 +1,7 @@
+-- Units of each currency per unit of a common reference currency. Only the
+-- ratios between rows are used.
+CREATE TABLE exchange_rates (
+    currency   CHAR(3) PRIMARY KEY,
+    rate       DOUBLE PRECISION NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user'
+        CHECK (role IN ('user', 'admin'));
+CREATE INDEX idx_users_role ON users (role);
@@ -0,0 +1,13 @@
+CREATE TABLE invites (
+    id                 BIGSERIAL PRIMARY KEY,
+    code_hash          VARCHAR(64) NOT NULL,
+    email              VARCHAR(320),
+    created_by_user_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    expires_at         TIMESTAMPTZ NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    used_at            TIMESTAMPTZ,
+    used_by_user_id    BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    revoked_at         TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_invites_code_hash ON invites (code_hash);
+CREATE INDEX idx_invites_created_by_user_id ON invites (created_by_user_id);
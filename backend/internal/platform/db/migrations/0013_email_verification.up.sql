@@ -0,0 +1,16 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+-- Accounts created before verification existed are trusted as they are.
+UPDATE users SET email_verified_at = COALESCE(created, now());
+
+CREATE TABLE one_time_tokens (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email      VARCHAR(320) NOT NULL,
+    purpose    VARCHAR(24) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    used_at    TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_one_time_tokens_token_hash ON one_time_tokens (token_hash);
+CREATE INDEX idx_one_time_tokens_user_id_purpose ON one_time_tokens (user_id, purpose);
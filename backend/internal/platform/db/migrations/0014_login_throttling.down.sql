@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS auth_audit_events;
+DROP TABLE IF EXISTS login_throttles;
@@ -0,0 +1,19 @@
+CREATE TABLE login_throttles (
+    key                 VARCHAR(400) PRIMARY KEY,
+    failures            INT NOT NULL,
+    last_failure_at     TIMESTAMPTZ NOT NULL,
+    previous_failure_at TIMESTAMPTZ
+);
+CREATE INDEX idx_login_throttles_last_failure_at ON login_throttles (last_failure_at);
+
+CREATE TABLE auth_audit_events (
+    id         BIGSERIAL PRIMARY KEY,
+    type       VARCHAR(32) NOT NULL,
+    user_id    BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    email      VARCHAR(320),
+    ip         VARCHAR(64),
+    until      TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_auth_audit_events_user_id ON auth_audit_events (user_id);
+CREATE INDEX idx_auth_audit_events_created_at ON auth_audit_events (created_at);
@@ -0,0 +1,130 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+
+	"appliedTo/internal/platform/validate"
+)
+
+// contactGroup is one contact the backfill creates and the applications
+// naming it.
+type contactGroup struct {
+	userID       int64
+	email        sql.NullString
+	names        []string
+	applications []int64
+	companies    []int64
+}
+
+// backfillContacts turns the contact_name/contact_email of existing
+// applications into contacts: one per email address, and one per name and
+// company for contacts without an address. Addresses are normalized with
+// validate.NormalizeAndValidateEmail, as the API does; invalid ones are
+// ignored. Contacts are linked to the application and to its company.
+func backfillContacts(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, user_id, company_id, btrim(COALESCE(contact_name, '')), COALESCE(contact_email, '')
+		FROM job_applications
+		WHERE NULLIF(btrim(contact_name), '') IS NOT NULL
+		   OR NULLIF(btrim(contact_email), '') IS NOT NULL
+		ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type groupKey struct {
+		userID    int64
+		email     string
+		name      string
+		companyID sql.NullInt64
+	}
+	groups := map[groupKey]*contactGroup{}
+	var order []groupKey
+	for rows.Next() {
+		var (
+			id, userID  int64
+			companyID   sql.NullInt64
+			name, email string
+		)
+		if err := rows.Scan(&id, &userID, &companyID, &name, &email); err != nil {
+			return err
+		}
+		k := groupKey{userID: userID}
+		if normalized, err := validate.NormalizeAndValidateEmail(email); err == nil {
+			k.email = normalized
+		} else if name == "" {
+			continue
+		} else {
+			k.name, k.companyID = strings.ToLower(name), companyID
+		}
+		g, ok := groups[k]
+		if !ok {
+			g = &contactGroup{userID: userID, email: sql.NullString{String: k.email, Valid: k.email != ""}}
+			groups[k] = g
+			order = append(order, k)
+		}
+		if name != "" {
+			g.names = append(g.names, name)
+		}
+		g.applications = append(g.applications, id)
+		if companyID.Valid && !slices.Contains(g.companies, companyID.Int64) {
+			g.companies = append(g.companies, companyID.Int64)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, k := range order {
+		g := groups[k]
+		var contactID int64
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO contacts (user_id, name, email) VALUES ($1, $2, $3) RETURNING id`,
+			g.userID, g.name(), g.email).Scan(&contactID)
+		if err != nil {
+			return err
+		}
+		for _, id := range g.applications {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO job_application_contacts (job_application_id, contact_id) VALUES ($1, $2)
+				 ON CONFLICT DO NOTHING`, id, contactID); err != nil {
+				return err
+			}
+		}
+		for _, id := range g.companies {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO company_contacts (company_id, contact_id) VALUES ($1, $2)
+				 ON CONFLICT DO NOTHING`, id, contactID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// name is the name given most often, the first in sort order among equals,
+// or else the local part of the email address.
+func (g *contactGroup) name() string {
+	if len(g.names) == 0 {
+		local, _, _ := strings.Cut(g.email.String, "@")
+		return local
+	}
+	names := slices.Clone(g.names)
+	slices.Sort(names)
+	best, bestCount := "", 0
+	for i := 0; i < len(names); {
+		j := i
+		for j < len(names) && names[j] == names[i] {
+			j++
+		}
+		if j-i > bestCount {
+			best, bestCount = names[i], j-i
+		}
+		i = j
+	}
+	return best
+}
@@ -0,0 +1,18 @@
+// Package migrations embeds the versioned SQL schema migrations. Add new ones
+// with `go run ./cmd/migrate create <name>`.
+package migrations
+
+import (
+	"embed"
+
+	"appliedTo/internal/platform/db/migrate"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// Funcs are the Go steps of migrations, by version. Each runs after the up
+// SQL of its version, in the same transaction.
+var Funcs = map[int64]migrate.Func{
+	7: backfillContacts,
+}
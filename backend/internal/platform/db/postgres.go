@@ -1,9 +1,10 @@
 package db
 
 import (
-	"appliedTo/internal/app/jobapplication"
-	"appliedTo/internal/app/user"
 	"appliedTo/internal/platform/config"
+	"appliedTo/internal/platform/db/migrate"
+	"appliedTo/internal/platform/db/migrations"
+	"context"
 	"fmt"
 	"log"
 
@@ -16,7 +17,8 @@ func Connect(cfg config.Config) (*gorm.DB, error) {
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
 		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort, cfg.DBSSLMode, cfg.DBTimeZone,
 	)
-	g, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError turns unique violations into gorm.ErrDuplicatedKey.
+	g, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
@@ -37,18 +39,45 @@ func Connect(cfg config.Config) (*gorm.DB, error) {
 	return g, nil
 }
 
-func Migrate(g *gorm.DB) error {
-	entities := []any{
-		&user.User{},
-		&jobapplication.JobApplication{},
-		&jobapplication.Employment{},
-		&jobapplication.SalaryRange{},
+// NewMigrator returns a migrator over the embedded SQL migrations and their
+// Go steps.
+func NewMigrator(g *gorm.DB) (*migrate.Migrator, error) {
+	sqlDB, err := g.DB()
+	if err != nil {
+		return nil, err
 	}
-	for _, e := range entities {
-		if err := g.AutoMigrate(e); err != nil {
-			return fmt.Errorf("migrate %T: %w", e, err)
-		}
-		log.Printf("migrated %T", e)
+	ms, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	if ms, err = migrate.WithFuncs(ms, migrations.Funcs); err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, ms), nil
+}
+
+// Migrate checks the schema on start-up. Pending migrations are applied when
+// apply is set; otherwise they are an error so that the server never runs
+// against a schema it does not expect.
+func Migrate(ctx context.Context, g *gorm.DB, apply bool) error {
+	m, err := NewMigrator(g)
+	if err != nil {
+		return err
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if !apply {
+		return fmt.Errorf("%d pending migration(s), first %s: run `migrate up` or set DB_MIGRATE_ON_START=true", len(pending), pending[0])
+	}
+
+	done, err := m.Up(ctx)
+	for _, mg := range done {
+		log.Printf("migrated %s", mg)
 	}
-	return nil
+	return err
 }
@@ -1,45 +1,101 @@
 package middleware
 
 import (
-	"appliedTo/internal/utils"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+
+	"appliedTo/internal/platform/http/problem"
+	"appliedTo/internal/platform/security/token"
 )
 
-func JWTMiddleware() gin.HandlerFunc {
-    return func(c *gin.Context) {
-        authHeader := c.GetHeader("Authorization")
-        if authHeader == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token required"})
-            c.Abort()
-            return
-        }
-
-        parts := strings.Split(authHeader, " ")
-        if len(parts) != 2 || parts[0] != "Bearer" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
-            c.Abort()
-            return
-        }
-
-        tokenString := parts[1]
-
-        claims := &utils.Claims{}
-        token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-            return utils.JwtSecret, nil
-        })
-        
-        if err != nil || !token.Valid {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-            c.Abort()
-            return
-        }
-
-        c.Set("user_id", claims.UserID)
-        c.Next()
-    }
+const CtxKeyPrincipal = "principal"
+
+// DefaultRole is assumed for tokens that carry no role claim.
+const DefaultRole = "user"
+
+// Principal is the authenticated caller as taken from the access token.
+type Principal struct {
+	UserID uint
+	Email  string
+	Role   string
+}
+
+// RequireAuth verifies the bearer token with the same token.JWT that signs it
+// and stores the resulting Principal on the context.
+func RequireAuth(j *token.JWT) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, raw, found := strings.Cut(strings.TrimSpace(c.GetHeader("Authorization")), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(raw) == "" {
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Authorization token required")
+			return
+		}
+
+		claims, err := j.Verify(strings.TrimSpace(raw))
+		if err != nil {
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid or expired token")
+			return
+		}
+
+		sub, _ := claims["sub"].(string)
+		u64, err := strconv.ParseUint(sub, 10, 64)
+		if err != nil || u64 == 0 || uint64(uint(u64)) != u64 {
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Invalid or expired token")
+			return
+		}
+		email, _ := claims["eml"].(string)
+		role, _ := claims["rol"].(string)
+		if role == "" {
+			role = DefaultRole
+		}
+
+		c.Set(CtxKeyPrincipal, Principal{UserID: uint(u64), Email: email, Role: role})
+		c.Next()
+	}
 }
 
+// RequireRole lets callers with one of the given roles through. It runs after
+// RequireAuth and answers 401 without a principal and 403 for other roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := PrincipalFrom(c)
+		if !ok {
+			problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Authentication required")
+			return
+		}
+		if !slices.Contains(roles, p.Role) {
+			problem.Abort(c, http.StatusForbidden, problem.CodeForbidden, "Insufficient permissions")
+			return
+		}
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns the Principal stored by RequireAuth, if any.
+func PrincipalFrom(c *gin.Context) (Principal, bool) {
+	v, ok := c.Get(CtxKeyPrincipal)
+	if !ok {
+		return Principal{}, false
+	}
+	p, ok := v.(Principal)
+	return p, ok
+}
+
+// AuthUserID returns the ID of the authenticated caller, if any.
+func AuthUserID(c *gin.Context) (uint, bool) {
+	p, ok := PrincipalFrom(c)
+	return p.UserID, ok && p.UserID != 0
+}
+
+// RequireAuthUserID returns the ID of the authenticated caller the request
+// acts on behalf of. It aborts with 401 when there is none.
+func RequireAuthUserID(c *gin.Context) (uint, bool) {
+	userID, ok := AuthUserID(c)
+	if !ok {
+		problem.Abort(c, http.StatusUnauthorized, problem.CodeUnauthorized, "Authentication required")
+	}
+	return userID, ok
+}
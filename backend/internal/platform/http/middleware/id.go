@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	"appliedTo/internal/platform/http/problem"
 )
 
 func requireUintParam(param, ctxKey, label string) gin.HandlerFunc {
@@ -13,14 +15,12 @@ func requireUintParam(param, ctxKey, label string) gin.HandlerFunc {
 		raw := strings.TrimSpace(c.Param(param))
 		u64, err := strconv.ParseUint(raw, 10, 64)
 		if err != nil || u64 == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + label})
-			c.Abort()
+			problem.Abort(c, http.StatusBadRequest, CodeInvalidID, "Invalid "+label)
 			return
 		}
 		u := uint(u64)
 		if uint64(u) != u64 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + label})
-			c.Abort()
+			problem.Abort(c, http.StatusBadRequest, CodeInvalidID, "Invalid "+label)
 			return
 		}
 		c.Set(ctxKey, u)
@@ -28,10 +28,22 @@ func requireUintParam(param, ctxKey, label string) gin.HandlerFunc {
 	}
 }
 
+const CodeInvalidID = "invalid_id"
+
 const (
 	CtxKeyUserID           = "userID"
 	CtxKeyJobApplicationID = "jobApplicationID"
+	CtxKeyInterviewID      = "interviewID"
+	CtxKeyCompanyID        = "companyID"
+	CtxKeyContactID        = "contactID"
+	CtxKeyDocumentID       = "documentID"
+	CtxKeyInviteID         = "inviteID"
 )
 
 func RequireUserID() gin.HandlerFunc           { return requireUintParam("id", CtxKeyUserID, "user id") }
 func RequireJobApplicationID() gin.HandlerFunc { return requireUintParam("id", CtxKeyJobApplicationID, "job application id") }
+func RequireInterviewID() gin.HandlerFunc      { return requireUintParam("interviewId", CtxKeyInterviewID, "interview id") }
+func RequireCompanyID() gin.HandlerFunc        { return requireUintParam("id", CtxKeyCompanyID, "company id") }
+func RequireContactID() gin.HandlerFunc        { return requireUintParam("id", CtxKeyContactID, "contact id") }
+func RequireDocumentID() gin.HandlerFunc       { return requireUintParam("documentId", CtxKeyDocumentID, "document id") }
+func RequireInviteID() gin.HandlerFunc         { return requireUintParam("id", CtxKeyInviteID, "invite id") }
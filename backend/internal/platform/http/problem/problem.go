@@ -0,0 +1,172 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"appliedTo/internal/platform/validate"
+)
+
+const (
+	ContentType = "application/problem+json"
+
+	// HeaderRequestID is set on every response by middleware.RequestID and
+	// copied into each problem so clients can quote it.
+	HeaderRequestID = "X-Request-ID"
+
+	typePrefix = "urn:appliedto:problem:"
+)
+
+// Stable codes shared by all endpoints. Domain packages register their own.
+const (
+	CodeInvalidPayload   = "invalid_payload"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeInternal         = "internal_error"
+)
+
+// Problem is the error body of every endpoint, an RFC 7807 problem details
+// document extended with a stable machine-readable code, the request ID and,
+// for validation failures, the offending fields. Errors implementing Extender
+// add further members, which are written next to the standard ones.
+type Problem struct {
+	Type       string                `json:"type"`
+	Title      string                `json:"title"`
+	Status     int                   `json:"status"`
+	Detail     string                `json:"detail,omitempty"`
+	Instance   string                `json:"instance,omitempty"`
+	Code       string                `json:"code"`
+	RequestID  string                `json:"requestId,omitempty"`
+	Errors     []validate.FieldError `json:"errors,omitempty"`
+	Extensions map[string]any        `json:"-"`
+}
+
+// Extender is implemented by errors whose problem carries extension members,
+// such as the records a request conflicts with.
+type Extender interface {
+	ProblemExtensions() map[string]any
+}
+
+// MarshalJSON flattens Extensions into the document. Extension members never
+// replace standard ones.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	b, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	members := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	for k, v := range p.Extensions {
+		if _, taken := members[k]; taken {
+			continue
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		members[k] = raw
+	}
+	return json.Marshal(members)
+}
+
+func New(status int, code, title string) Problem {
+	return Problem{Type: typePrefix + code, Title: title, Status: status, Code: code}
+}
+
+// ---- error mapping ----
+
+type mapping struct {
+	target error
+	status int
+	code   string
+	title  string
+}
+
+var (
+	mu       sync.RWMutex
+	mappings []mapping
+)
+
+// Register maps errors matching target (errors.Is) to a problem. Packages
+// register their sentinel errors once at init so handlers can pass any
+// service error to Error.
+func Register(target error, status int, code, title string) {
+	mu.Lock()
+	defer mu.Unlock()
+	mappings = append(mappings, mapping{target: target, status: status, code: code, title: title})
+}
+
+func init() {
+	Register(gorm.ErrRecordNotFound, http.StatusNotFound, CodeNotFound, "Resource not found")
+	Register(gorm.ErrDuplicatedKey, http.StatusConflict, CodeConflict, "Resource already exists")
+}
+
+// From converts err to a problem. Validation failures carry their field
+// errors; unregistered errors become an opaque 500.
+func From(err error) Problem {
+	var fields validate.Errors
+	if errors.As(err, &fields) {
+		p := New(http.StatusBadRequest, CodeValidationFailed, "Validation failed")
+		p.Errors = fields
+		return p
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, m := range mappings {
+		if errors.Is(err, m.target) {
+			p := New(m.status, m.code, m.title)
+			var ext Extender
+			if errors.As(err, &ext) {
+				p.Extensions = ext.ProblemExtensions()
+			}
+			return p
+		}
+	}
+	return New(http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
+
+// ---- writers ----
+
+// Write sends p and aborts the handler chain.
+func Write(c *gin.Context, p Problem) {
+	p.RequestID = c.Writer.Header().Get(HeaderRequestID)
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// Error maps err and writes it. Unexpected errors are logged, never echoed.
+func Error(c *gin.Context, err error) {
+	p := From(err)
+	if p.Status >= http.StatusInternalServerError {
+		log.Printf("request %s %s %s: %v", c.Writer.Header().Get(HeaderRequestID), c.Request.Method, c.Request.URL.Path, err)
+	}
+	Write(c, p)
+}
+
+// Abort writes a problem that does not originate from an error value, such
+// as a malformed request.
+func Abort(c *gin.Context, status int, code, title string) {
+	Write(c, New(status, code, title))
+}
+
+// InvalidPayload reports a request body or query that could not be bound.
+func InvalidPayload(c *gin.Context) {
+	Abort(c, http.StatusBadRequest, CodeInvalidPayload, "Invalid request payload")
+}
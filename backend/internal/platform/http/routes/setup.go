@@ -2,11 +2,13 @@ package routes
 
 import (
 	"log"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "appliedTo/docs"
+	"appliedTo/internal/platform/http/problem"
 )
 
 type RouteConfig struct {
@@ -15,6 +17,14 @@ type RouteConfig struct {
 	Register func(*gin.RouterGroup)
 }
 
+// AdminRouteOpts are the middlewares of the admin routes. RequireID parses
+// the ID of the resource an admin route acts on, where it has one.
+type AdminRouteOpts struct {
+	RequireAuth  gin.HandlerFunc
+	RequireAdmin gin.HandlerFunc
+	RequireID    gin.HandlerFunc
+}
+
 func SetupRoutes(r *gin.Engine, basePath string, config ...RouteConfig) {
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -26,5 +36,9 @@ func SetupRoutes(r *gin.Engine, basePath string, config ...RouteConfig) {
 		conf.Register(g)
 	}
 
+	r.NoRoute(func(c *gin.Context) {
+		problem.Abort(c, http.StatusNotFound, problem.CodeNotFound, "Route not found")
+	})
+
 	log.Println("Routes setup completed")
 }
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryNotifier keeps messages instead of sending them, for tests.
+type MemoryNotifier struct {
+	mu   sync.Mutex
+	msgs []Message
+}
+
+func NewMemory() *MemoryNotifier { return &MemoryNotifier{} }
+
+func (n *MemoryNotifier) Notify(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.msgs = append(n.msgs, msg)
+	return nil
+}
+
+// Messages returns the messages received so far, oldest first.
+func (n *MemoryNotifier) Messages() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Message(nil), n.msgs...)
+}
+
+// Reset forgets all messages.
+func (n *MemoryNotifier) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.msgs = nil
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// Message is a notification addressed to one recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	// Secrets are parts of Body only the recipient may see, such as the
+	// token of a password reset link. Notifiers that do not deliver the
+	// message must not reveal them.
+	Secrets []string
+}
+
+// Notifier delivers messages, such as reminders and account mail.
+// Implementations must be safe for concurrent use.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// LogNotifier writes messages to the standard logger instead of sending them.
+// Their Secrets are redacted, so account mail it "sends" cannot be used.
+type LogNotifier struct{}
+
+func NewLog() LogNotifier { return LogNotifier{} }
+
+func (LogNotifier) Notify(_ context.Context, msg Message) error {
+	body := msg.Body
+	for _, s := range msg.Secrets {
+		if s != "" {
+			body = strings.ReplaceAll(body, s, "[redacted]")
+		}
+	}
+	log.Printf("notify to=%s subject=%q\n%s", msg.To, msg.Subject, body)
+	return nil
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestLogNotifierRedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(prev) })
+
+	err := NewLog().Notify(context.Background(), Message{
+		To:      "jane@example.com",
+		Subject: "Reset your password",
+		Body:    "https://app.example.com/reset-password?token=s3cr3t\n\nToken: s3cr3t",
+		Secrets: []string{"s3cr3t"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("log reveals the secret:\n%s", out)
+	}
+	if !strings.Contains(out, "reset-password?token=[redacted]") {
+		t.Errorf("log lacks the redacted link:\n%s", out)
+	}
+}
@@ -0,0 +1,221 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a minimal SMTP server without TLS or auth that accepts every
+// message and hands over the raw DATA of each on Messages.
+type fakeSMTP struct {
+	ln       net.Listener
+	Messages chan fakeMail
+}
+
+type fakeMail struct {
+	From, To string
+	Data     string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeSMTP{ln: ln, Messages: make(chan fakeMail, 10)}
+	t.Cleanup(func() { ln.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeSMTP) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var mail fakeMail
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		switch verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 fake")
+		case "MAIL":
+			mail.From = strings.TrimPrefix(cmd, "MAIL FROM:")
+			reply("250 OK")
+		case "RCPT":
+			mail.To = strings.TrimPrefix(cmd, "RCPT TO:")
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			mail.Data = data.String()
+			f.Messages <- mail
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("500 unknown command")
+		}
+	}
+}
+
+func newTestSMTP(t *testing.T, addr string, timeout time.Duration) *SMTPNotifier {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(addr)
+	n, err := NewSMTP(SMTPConfig{Host: host, Port: port, From: "noreply@appliedto.test", Timeout: timeout})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestSMTPNotifierSends(t *testing.T) {
+	srv := newFakeSMTP(t)
+	n := newTestSMTP(t, srv.ln.Addr().String(), 5*time.Second)
+
+	err := n.Notify(context.Background(), Message{
+		To:      "jane@example.com",
+		Subject: "Follow up: Gopher at Grüne GmbH",
+		Body:    "Hi Jane,\n\n.leading dot\r\nGood luck!",
+	})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	var mail fakeMail
+	select {
+	case mail = <-srv.Messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+	if mail.From != "<noreply@appliedto.test>" {
+		t.Errorf("MAIL FROM:%s, want <noreply@appliedto.test>", mail.From)
+	}
+	if mail.To != "<jane@example.com>" {
+		t.Errorf("RCPT TO:%s, want <jane@example.com>", mail.To)
+	}
+
+	header, body, ok := strings.Cut(mail.Data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("message has no header/body separator:\n%q", mail.Data)
+	}
+	for _, want := range []string{
+		"To: jane@example.com",
+		"Subject: =?utf-8?q?Follow_up:_Gopher_at_Gr=C3=BCne_GmbH?=",
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: 8bit",
+	} {
+		if !strings.Contains(header+"\r\n", want+"\r\n") {
+			t.Errorf("header lacks %q:\n%s", want, header)
+		}
+	}
+	if !strings.HasPrefix(header, "From: noreply@appliedto.test\r\n") {
+		t.Errorf("header does not start with From:\n%s", header)
+	}
+	if !strings.Contains(header, "\r\nDate: ") {
+		t.Errorf("header lacks Date:\n%s", header)
+	}
+	if want := "Hi Jane,\r\n\r\n.leading dot\r\nGood luck!\r\n"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestSMTPNotifierRejectsHeaderInjection(t *testing.T) {
+	srv := newFakeSMTP(t)
+	n := newTestSMTP(t, srv.ln.Addr().String(), 5*time.Second)
+
+	for _, to := range []string{"jane@example.com\r\nBcc: eve@example.com", "jane@example.com\nBcc: eve@example.com"} {
+		if err := n.Notify(context.Background(), Message{To: to, Subject: "Hi", Body: "Hi"}); err == nil {
+			t.Errorf("Notify(To: %q) succeeded", to)
+		}
+	}
+	select {
+	case mail := <-srv.Messages:
+		t.Errorf("sent a message to %s", mail.To)
+	default:
+	}
+}
+
+func TestSMTPNotifierGivesUpOnStalledServer(t *testing.T) {
+	// The server accepts connections but never greets.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+	msg := Message{To: "jane@example.com", Subject: "Hi", Body: "Hi"}
+
+	t.Run("timeout", func(t *testing.T) {
+		n := newTestSMTP(t, ln.Addr().String(), 100*time.Millisecond)
+		start := time.Now()
+		if err := n.Notify(context.Background(), msg); err == nil {
+			t.Fatal("Notify() succeeded")
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			t.Errorf("Notify() took %s", d)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		n := newTestSMTP(t, ln.Addr().String(), time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		start := time.Now()
+		err := n.Notify(ctx, msg)
+		var netErr net.Error
+		if err == nil || !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Fatalf("Notify() error = %v, want a timeout", err)
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			t.Errorf("Notify() took %s", d)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+// Package lockout slows down and temporarily locks out repeated failed
+// attempts, such as password guesses, per key.
+package lockout
+
+import (
+	"context"
+	"time"
+)
+
+// State is what a Store keeps per key: the failures since the key was last
+// reset or idle for a whole window, and when the last two happened.
+type State struct {
+	Failures      int
+	LastFailureAt time.Time
+	// PreviousFailureAt is zero when the last failure started the count.
+	PreviousFailureAt time.Time
+}
+
+// Store counts failures per key. Fail must be atomic so that concurrent
+// attempts, possibly on other replicas, are all counted. Implementations
+// must be safe for concurrent use.
+type Store interface {
+	// Get returns the state of key, or the zero State if its last failure
+	// is before since.
+	Get(ctx context.Context, key string, since time.Time) (State, error)
+	// Fail records a failure at now and returns the new state. A key whose
+	// last failure is before since starts counting again.
+	Fail(ctx context.Context, key string, now, since time.Time) (State, error)
+	// Release takes back one failure of key, counted for an attempt that
+	// turned out not to fail.
+	Release(ctx context.Context, key string) error
+	// Reset forgets key.
+	Reset(ctx context.Context, key string) error
+}
+
+// ---- Options pattern ----
+
+type Option func(*Limiter)
+
+// WithFreeAttempts sets how many failures are allowed before backoff starts.
+func WithFreeAttempts(n int) Option {
+	return func(l *Limiter) {
+		if n >= 0 {
+			l.free = n
+		}
+	}
+}
+
+// WithBackoff sets the delay after the first failure beyond the free ones,
+// which doubles with every further failure up to max.
+func WithBackoff(base, max time.Duration) Option {
+	return func(l *Limiter) {
+		if base > 0 && max >= base {
+			l.base, l.max = base, max
+		}
+	}
+}
+
+// WithLockout locks the key for d once it reaches n failures, and again
+// for every failure after a lockout ended.
+func WithLockout(n int, d time.Duration) Option {
+	return func(l *Limiter) {
+		if n > 0 && d > 0 {
+			l.lockAfter, l.lockFor = n, d
+		}
+	}
+}
+
+// WithCountRefused decides whether attempts refused while the key has to
+// wait count as failures. Counting them, the default, keeps a key that keeps
+// trying blocked. Not counting them keeps others from extending the lockout
+// of a key they do not own, such as an account, by trying it.
+func WithCountRefused(count bool) Option {
+	return func(l *Limiter) { l.countRefused = count }
+}
+
+// WithWindow sets how long a key must stay free of failures to start over.
+func WithWindow(d time.Duration) Option {
+	return func(l *Limiter) {
+		if d > 0 {
+			l.window = d
+		}
+	}
+}
+
+// ---- Limiter ----
+
+// Limiter applies a backoff and lockout policy to the keys of one kind,
+// such as accounts or client IPs. Limiters sharing a Store need distinct
+// prefixes.
+type Limiter struct {
+	store     Store
+	prefix    string
+	free      int
+	base      time.Duration
+	max       time.Duration
+	lockAfter int
+	lockFor   time.Duration
+	window    time.Duration
+	countRefused bool
+	now          func() time.Time
+}
+
+func New(store Store, prefix string, opts ...Option) *Limiter {
+	l := &Limiter{
+		store:        store,
+		prefix:       prefix,
+		free:         3,
+		base:         time.Second,
+		max:          5 * time.Minute,
+		lockAfter:    10,
+		lockFor:      15 * time.Minute,
+		window:       24 * time.Hour,
+		countRefused: true,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Result is the outcome of a reserved attempt.
+type Result struct {
+	Failures int
+	// Wait is how long the key still had to wait. The attempt must be
+	// refused when it is not zero.
+	Wait time.Duration
+	// Locked is set when this attempt locks the key out, should it fail.
+	Locked bool
+	// BlockedUntil is when the next attempt is allowed if this one fails;
+	// zero if right away.
+	BlockedUntil time.Time
+}
+
+// Attempt reserves an attempt of key by counting it as a failure before it
+// is made, so that concurrent attempts cannot all slip past the limit. A
+// refused attempt stays counted unless the limiter is set not to count
+// refused attempts (see WithCountRefused). An allowed attempt that succeeds
+// must be followed by Reset or Release.
+func (l *Limiter) Attempt(ctx context.Context, key string) (Result, error) {
+	now := l.now()
+	since := now.Add(-l.window)
+	if !l.countRefused {
+		st, err := l.store.Get(ctx, l.prefix+key, since)
+		if err != nil {
+			return Result{}, err
+		}
+		if until := l.blockedUntil(st); until.After(now) {
+			return Result{Failures: st.Failures, Wait: until.Sub(now), BlockedUntil: until}, nil
+		}
+	}
+
+	st, err := l.store.Fail(ctx, l.prefix+key, now, since)
+	if err != nil {
+		return Result{}, err
+	}
+	res := Result{Failures: st.Failures, BlockedUntil: l.blockedUntil(st)}
+	prev := State{Failures: st.Failures - 1, LastFailureAt: st.PreviousFailureAt}
+	if until := l.blockedUntil(prev); until.After(now) {
+		if l.countRefused {
+			res.Wait = res.BlockedUntil.Sub(now)
+			return res, nil
+		}
+		// A concurrent failure blocked the key since Get; take the attempt
+		// back and wait for that failure only.
+		if err := l.store.Release(ctx, l.prefix+key); err != nil {
+			return Result{}, err
+		}
+		return Result{Failures: prev.Failures, Wait: until.Sub(now), BlockedUntil: until}, nil
+	}
+	res.Locked = st.Failures >= l.lockAfter
+	return res, nil
+}
+
+// Release takes back an attempt of key that did not fail, keeping its
+// earlier failures.
+func (l *Limiter) Release(ctx context.Context, key string) error {
+	return l.store.Release(ctx, l.prefix+key)
+}
+
+// Reset forgets the failures of key, after a successful attempt.
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, l.prefix+key)
+}
+
+func (l *Limiter) blockedUntil(st State) time.Time {
+	switch {
+	case st.Failures >= l.lockAfter:
+		return st.LastFailureAt.Add(l.lockFor)
+	case st.Failures > l.free:
+		delay := l.max
+		if n := st.Failures - l.free - 1; n < 32 {
+			delay = min(l.base<<n, l.max)
+		}
+		return st.LastFailureAt.Add(delay)
+	}
+	return time.Time{}
+}
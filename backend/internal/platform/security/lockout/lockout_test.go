@@ -0,0 +1,305 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"appliedTo/internal/platform/db/dbtest"
+)
+
+var t0 = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestBlockedUntil(t *testing.T) {
+	l := New(NewMemory(), "test:",
+		WithFreeAttempts(3),
+		WithBackoff(time.Second, time.Minute),
+		WithLockout(10, 15*time.Minute),
+	)
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{"none", 0, 0},
+		{"free", 3, 0},
+		{"first backoff", 4, time.Second},
+		{"doubles", 5, 2 * time.Second},
+		{"doubles again", 7, 8 * time.Second},
+		{"capped at max", 9, 32 * time.Second},
+		{"locked out", 10, 15 * time.Minute},
+		{"stays locked out", 25, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := l.blockedUntil(State{Failures: tt.failures, LastFailureAt: t0})
+			if tt.want == 0 {
+				if !got.IsZero() {
+					t.Errorf("blockedUntil() = %v, want zero", got)
+				}
+				return
+			}
+			if want := t0.Add(tt.want); !got.Equal(want) {
+				t.Errorf("blockedUntil() = +%v, want +%v", got.Sub(t0), tt.want)
+			}
+		})
+	}
+}
+
+func TestBlockedUntilShiftCap(t *testing.T) {
+	// Without a lockout in reach, the shift would overflow past 32 doublings.
+	l := New(NewMemory(), "test:",
+		WithFreeAttempts(0),
+		WithBackoff(time.Second, time.Hour),
+		WithLockout(1000, time.Minute),
+	)
+	for _, failures := range []int{13, 32, 33, 34, 100, 999} {
+		got := l.blockedUntil(State{Failures: failures, LastFailureAt: t0})
+		if want := t0.Add(time.Hour); !got.Equal(want) {
+			t.Errorf("blockedUntil(%d failures) = +%v, want +1h", failures, got.Sub(t0))
+		}
+	}
+}
+
+// step is one attempt at offset after t0 and what the limiter should say.
+type step struct {
+	after    time.Duration
+	wait     time.Duration
+	locked   bool
+	failures int
+}
+
+func TestAttempt(t *testing.T) {
+	tests := []struct {
+		name         string
+		lockAfter    int
+		countRefused bool
+		steps        []step
+	}{
+		{
+			name:         "free attempts, then backoff",
+			lockAfter:    10,
+			countRefused: true,
+			steps: []step{
+				{after: 0, failures: 1},
+				{after: 0, failures: 2},
+				{after: 0, failures: 3},
+				// Three failures are free, so the fourth attempt is allowed.
+				{after: 0, failures: 4},
+				// Refused attempts count too.
+				{after: 0, failures: 5, wait: 2 * time.Second},
+				{after: 2 * time.Second, failures: 6},
+				{after: 3 * time.Second, failures: 7, wait: 8 * time.Second},
+				{after: 11 * time.Second, failures: 8},
+			},
+		},
+		{
+			name:         "lockout, refused attempts counted",
+			lockAfter:    5,
+			countRefused: true,
+			steps: []step{
+				{after: 0, failures: 1},
+				{after: 0, failures: 2},
+				{after: 0, failures: 3},
+				{after: 0, failures: 4},
+				{after: time.Second, failures: 5, locked: true},
+				{after: time.Minute, failures: 6, wait: 10 * time.Minute},
+				// The refused attempt restarted the lockout.
+				{after: 10*time.Minute + time.Second, failures: 7, wait: 10 * time.Minute},
+				{after: 21 * time.Minute, failures: 8, locked: true},
+			},
+		},
+		{
+			name:      "lockout, refused attempts not counted",
+			lockAfter: 5,
+			steps: []step{
+				{after: 0, failures: 1},
+				{after: 0, failures: 2},
+				{after: 0, failures: 3},
+				{after: 0, failures: 4},
+				{after: 0, failures: 4, wait: time.Second},
+				{after: time.Second, failures: 5, locked: true},
+				{after: time.Minute, failures: 5, wait: 9*time.Minute + time.Second},
+				// Refused attempts leave the lockout as it was.
+				{after: 10 * time.Minute, failures: 5, wait: time.Second},
+				{after: 10*time.Minute + time.Second, failures: 6, locked: true},
+			},
+		},
+		{
+			name:      "idle for a window starts over",
+			lockAfter: 5,
+			steps: []step{
+				{after: 0, failures: 1},
+				{after: 0, failures: 2},
+				{after: 0, failures: 3},
+				{after: 0, failures: 4},
+				{after: 25 * time.Hour, failures: 1},
+				{after: 25 * time.Hour, failures: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runSteps(t, NewMemory(), tt.lockAfter, tt.countRefused, tt.steps)
+		})
+	}
+}
+
+// runSteps plays steps against a limiter with three free failures, backoff
+// from one second and a lockout of ten minutes after lockAfter failures.
+func runSteps(t *testing.T, store Store, lockAfter int, countRefused bool, steps []step) {
+	t.Helper()
+	var now time.Time
+	l := New(store, "test:",
+		WithFreeAttempts(3),
+		WithBackoff(time.Second, time.Minute),
+		WithLockout(lockAfter, 10*time.Minute),
+		WithWindow(24*time.Hour),
+		WithCountRefused(countRefused),
+	)
+	l.now = func() time.Time { return now }
+
+	for i, st := range steps {
+		now = t0.Add(st.after)
+		res, err := l.Attempt(context.Background(), "jane@example.com")
+		if err != nil {
+			t.Fatalf("step %d: Attempt() error = %v", i, err)
+		}
+		if res.Failures != st.failures || res.Wait != st.wait || res.Locked != st.locked {
+			t.Errorf("step %d: Attempt() = {failures %d, wait %v, locked %v}, want {failures %d, wait %v, locked %v}",
+				i, res.Failures, res.Wait, res.Locked, st.failures, st.wait, st.locked)
+		}
+	}
+}
+
+func TestAttemptReleaseAndReset(t *testing.T) {
+	l := New(NewMemory(), "test:", WithFreeAttempts(1), WithBackoff(time.Second, time.Minute))
+	l.now = func() time.Time { return t0 }
+	ctx := context.Background()
+
+	attempt := func() Result {
+		t.Helper()
+		res, err := l.Attempt(ctx, "k")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	attempt()
+	if err := l.Release(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if res := attempt(); res.Failures != 1 || res.Wait != 0 {
+		t.Errorf("after Release: %+v, want one failure and no wait", res)
+	}
+	if res := attempt(); res.Failures != 2 || res.Wait != 0 {
+		t.Errorf("second failure: %+v, want no wait", res)
+	}
+	if res := attempt(); res.Wait == 0 {
+		t.Errorf("third failure: %+v, want a wait", res)
+	}
+	if err := l.Reset(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if res := attempt(); res.Failures != 1 || res.Wait != 0 {
+		t.Errorf("after Reset: %+v, want one failure and no wait", res)
+	}
+}
+
+// TestStores checks that the Postgres store counts like the memory store.
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory":   func(*testing.T) Store { return NewMemory() },
+		"postgres": func(t *testing.T) Store { return NewPostgres(dbtest.New(t)) },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			ctx := context.Background()
+			since := t0.Add(-time.Hour)
+
+			fail := func(key string, now time.Time) State {
+				t.Helper()
+				st, err := store.Fail(ctx, key, now, since)
+				if err != nil {
+					t.Fatalf("Fail() error = %v", err)
+				}
+				return st
+			}
+
+			st := fail("a", t0)
+			if st.Failures != 1 || !st.LastFailureAt.Equal(t0) || !st.PreviousFailureAt.IsZero() {
+				t.Errorf("first Fail() = %+v", st)
+			}
+			st = fail("a", t0.Add(time.Second))
+			if st.Failures != 2 || !st.LastFailureAt.Equal(t0.Add(time.Second)) || !st.PreviousFailureAt.Equal(t0) {
+				t.Errorf("second Fail() = %+v", st)
+			}
+			if st := fail("b", t0); st.Failures != 1 {
+				t.Errorf("Fail() of another key = %+v", st)
+			}
+			if got, err := store.Get(ctx, "a", since); err != nil || !sameState(got, st) {
+				t.Errorf("Get() = %+v, %v, want %+v", got, err, st)
+			}
+			if got, err := store.Get(ctx, "a", t0.Add(2*time.Second)); err != nil || !sameState(got, State{}) {
+				t.Errorf("Get() of a stale key = %+v, %v, want zero", got, err)
+			}
+			if got, err := store.Get(ctx, "missing", since); err != nil || !sameState(got, State{}) {
+				t.Errorf("Get() of a missing key = %+v, %v, want zero", got, err)
+			}
+
+			if err := store.Release(ctx, "a"); err != nil {
+				t.Fatal(err)
+			}
+			if st := fail("a", t0.Add(2*time.Second)); st.Failures != 2 || !st.PreviousFailureAt.Equal(t0.Add(time.Second)) {
+				t.Errorf("Fail() after Release = %+v", st)
+			}
+
+			// A key idle since before since starts over.
+			if st := fail("stale", t0.Add(-2*time.Hour)); st.Failures != 1 {
+				t.Errorf("Fail() of a stale key = %+v", st)
+			}
+			if st := fail("stale", t0); st.Failures != 1 || !st.PreviousFailureAt.IsZero() {
+				t.Errorf("Fail() after a whole window = %+v", st)
+			}
+
+			if err := store.Reset(ctx, "a"); err != nil {
+				t.Fatal(err)
+			}
+			if st := fail("a", t0); st.Failures != 1 {
+				t.Errorf("Fail() after Reset = %+v", st)
+			}
+			if err := store.Release(ctx, "missing"); err != nil {
+				t.Errorf("Release() of a missing key error = %v", err)
+			}
+		})
+	}
+}
+
+// sameState compares states by instant, as times read back from Postgres
+// carry another location.
+func sameState(a, b State) bool {
+	return a.Failures == b.Failures && a.LastFailureAt.Equal(b.LastFailureAt) &&
+		a.PreviousFailureAt.Equal(b.PreviousFailureAt)
+}
+
+func TestPostgresStorePrunes(t *testing.T) {
+	db := dbtest.New(t)
+	store := NewPostgres(db)
+	ctx := context.Background()
+
+	if _, err := store.Fail(ctx, "old", t0.Add(-2*time.Hour), t0.Add(-3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Fail(ctx, "new", t0, t0.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	if err := db.Model(&throttleRow{}).Order("key").Pluck("key", &keys).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "new" {
+		t.Errorf("keys = %v, want only the one failed within the window", keys)
+	}
+}
@@ -0,0 +1,68 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryPruneAt is the number of keys from which Fail drops idle ones.
+const memoryPruneAt = 10000
+
+// MemoryStore keeps state in the process. It suits a single instance and
+// tests; replicas each count on their own.
+type MemoryStore struct {
+	mu   sync.Mutex
+	keys map[string]State
+}
+
+func NewMemory() *MemoryStore { return &MemoryStore{keys: map[string]State{}} }
+
+func (m *MemoryStore) Get(_ context.Context, key string, since time.Time) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.keys[key]
+	if st.LastFailureAt.Before(since) {
+		return State{}, nil
+	}
+	return st, nil
+}
+
+func (m *MemoryStore) Fail(_ context.Context, key string, now, since time.Time) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.keys) >= memoryPruneAt {
+		for k, st := range m.keys {
+			if st.LastFailureAt.Before(since) {
+				delete(m.keys, k)
+			}
+		}
+	}
+
+	st := m.keys[key]
+	if st.LastFailureAt.Before(since) {
+		st = State{}
+	}
+	st.Failures++
+	st.PreviousFailureAt, st.LastFailureAt = st.LastFailureAt, now
+	m.keys[key] = st
+	return st, nil
+}
+
+func (m *MemoryStore) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if st, ok := m.keys[key]; ok && st.Failures > 0 {
+		st.Failures--
+		m.keys[key] = st
+	}
+	return nil
+}
+
+func (m *MemoryStore) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, key)
+	return nil
+}
@@ -0,0 +1,100 @@
+package lockout
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// postgresPruneEvery is how often Fail drops the rows of keys that have been
+// idle for a whole window.
+const postgresPruneEvery = time.Minute
+
+// PostgresStore keeps state in the login_throttles table, so that all
+// replicas share the counters.
+type PostgresStore struct {
+	db *gorm.DB
+
+	mu       sync.Mutex
+	prunedAt time.Time
+}
+
+func NewPostgres(db *gorm.DB) *PostgresStore { return &PostgresStore{db: db} }
+
+type throttleRow struct {
+	Key               string `gorm:"primaryKey"`
+	Failures          int
+	LastFailureAt     time.Time
+	PreviousFailureAt *time.Time
+}
+
+func (throttleRow) TableName() string { return "login_throttles" }
+
+func (r throttleRow) state() State {
+	st := State{Failures: r.Failures, LastFailureAt: r.LastFailureAt}
+	if r.PreviousFailureAt != nil {
+		st.PreviousFailureAt = *r.PreviousFailureAt
+	}
+	return st
+}
+
+func (p *PostgresStore) Get(ctx context.Context, key string, since time.Time) (State, error) {
+	var rows []throttleRow
+	err := p.db.WithContext(ctx).Where("key = ? AND last_failure_at >= ?", key, since).
+		Limit(1).Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return State{}, err
+	}
+	return rows[0].state(), nil
+}
+
+func (p *PostgresStore) Fail(ctx context.Context, key string, now, since time.Time) (State, error) {
+	p.prune(ctx, now, since)
+
+	var row throttleRow
+	err := p.db.WithContext(ctx).Raw(`
+		INSERT INTO login_throttles (key, failures, last_failure_at) VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttles.last_failure_at < ? THEN 1
+			                ELSE login_throttles.failures + 1 END,
+			previous_failure_at = CASE WHEN login_throttles.last_failure_at < ? THEN NULL
+			                           ELSE login_throttles.last_failure_at END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, previous_failure_at`, key, now, since, since).
+		Scan(&row).Error
+	if err != nil {
+		return State{}, err
+	}
+	return row.state(), nil
+}
+
+func (p *PostgresStore) Release(ctx context.Context, key string) error {
+	return p.db.WithContext(ctx).Model(&throttleRow{}).
+		Where("key = ? AND failures > 0", key).
+		Update("failures", gorm.Expr("failures - 1")).Error
+}
+
+func (p *PostgresStore) Reset(ctx context.Context, key string) error {
+	return p.db.WithContext(ctx).Where("key = ?", key).Delete(&throttleRow{}).Error
+}
+
+// prune deletes the rows idle since before since, at most once every
+// postgresPruneEvery per process. Failures are only logged; the rows are
+// dropped on a later call.
+func (p *PostgresStore) prune(ctx context.Context, now, since time.Time) {
+	p.mu.Lock()
+	due := now.Sub(p.prunedAt) >= postgresPruneEvery
+	if due {
+		p.prunedAt = now
+	}
+	p.mu.Unlock()
+	if !due {
+		return
+	}
+	if err := p.db.WithContext(ctx).Where("last_failure_at < ?", since).Delete(&throttleRow{}).Error; err != nil {
+		log.Printf("prune login throttles: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2Prefix = "$argon2id$"
+
+// ---- Options pattern ----
+
+type Argon2Option func(*argon2Hasher)
+
+// WithArgon2Memory sets the memory cost in KiB.
+func WithArgon2Memory(kib uint32) Argon2Option {
+	return func(a *argon2Hasher) {
+		if kib > 0 {
+			a.memory = kib
+		}
+	}
+}
+
+// WithArgon2Time sets the number of passes over the memory.
+func WithArgon2Time(passes uint32) Argon2Option {
+	return func(a *argon2Hasher) {
+		if passes > 0 {
+			a.time = passes
+		}
+	}
+}
+
+// WithArgon2Parallelism sets the number of threads.
+func WithArgon2Parallelism(threads uint8) Argon2Option {
+	return func(a *argon2Hasher) {
+		if threads > 0 {
+			a.threads = threads
+		}
+	}
+}
+
+// ---- Implementation ----
+
+// argon2Hasher stores argon2id hashes in the PHC string format
+// ($argon2id$v=19$m=...,t=...,p=...$salt$key). It still verifies bcrypt
+// hashes, including prehashed long passwords, and reports them as needing a
+// rehash so that they migrate on the next login.
+type argon2Hasher struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	saltLen int
+	keyLen  uint32
+	legacy  Hasher
+}
+
+// NewArgon2id returns a Hasher with the RFC 9106 second recommended
+// parameters (64 MiB, 3 passes, 4 threads) unless overridden.
+func NewArgon2id(opts ...Argon2Option) Hasher {
+	h := &argon2Hasher{
+		memory:  64 * 1024,
+		time:    3,
+		threads: 4,
+		saltLen: 16,
+		keyLen:  32,
+		legacy:  NewBcrypt(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	// argon2 needs at least 8 KiB per thread.
+	h.memory = max(h.memory, 8*uint32(h.threads))
+	return h
+}
+
+func (a *argon2Hasher) Hash(password string) (string, error) {
+	if password == "" {
+		return "", errors.New("empty password")
+	}
+	salt := make([]byte, a.saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.time, a.memory, a.threads, a.keyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix, argon2.Version, a.memory, a.time, a.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (a *argon2Hasher) Verify(hash, password string) bool {
+	if !isArgon2(hash) {
+		return a.legacy.Verify(hash, password)
+	}
+	return verifyArgon2(hash, password)
+}
+
+func (a *argon2Hasher) NeedsRehash(hash string) bool {
+	if !isArgon2(hash) {
+		return true
+	}
+	p, err := parseArgon2(hash)
+	if err != nil {
+		return true
+	}
+	return p.memory < a.memory || p.time < a.time || p.threads != a.threads ||
+		len(p.salt) < a.saltLen || uint32(len(p.key)) < a.keyLen
+}
+
+// ---- helpers ----
+
+func isArgon2(hash string) bool { return strings.HasPrefix(hash, argon2Prefix) }
+
+// verifyArgon2 checks password against an argon2id hash with the parameters
+// the hash records.
+func verifyArgon2(hash, password string) bool {
+	p, err := parseArgon2(hash)
+	if err != nil {
+		return false
+	}
+	key := argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, uint32(len(p.key)))
+	return subtle.ConstantTimeCompare(key, p.key) == 1
+}
+
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func parseArgon2(hash string) (argon2Params, error) {
+	var p argon2Params
+	parts := strings.Split(hash, "$")
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	if len(parts) != 6 {
+		return p, errors.New("malformed argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, errors.New("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, errors.New("malformed argon2id parameters")
+	}
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, err
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return p, err
+	}
+	if p.time == 0 || p.threads == 0 || len(p.key) == 0 {
+		return p, errors.New("malformed argon2id parameters")
+	}
+	return p, nil
+}
@@ -0,0 +1,137 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+// cheap keeps the tests fast; the parameters are not the point.
+var cheap = []Argon2Option{WithArgon2Memory(64), WithArgon2Time(1), WithArgon2Parallelism(1)}
+
+func TestArgon2RoundTrip(t *testing.T) {
+	h := NewArgon2id(cheap...)
+	for _, pw := range []string{"correct horse", strings.Repeat("ü", 100)} {
+		hash, err := h.Hash(pw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+			t.Errorf("Hash() = %q", hash)
+		}
+		if !h.Verify(hash, pw) {
+			t.Errorf("Verify() of the right password failed")
+		}
+		if h.Verify(hash, pw+"x") {
+			t.Errorf("Verify() of a wrong password succeeded")
+		}
+		if h.NeedsRehash(hash) {
+			t.Errorf("NeedsRehash() of a fresh hash")
+		}
+		if again, _ := h.Hash(pw); again == hash {
+			t.Errorf("two hashes of the same password are equal")
+		}
+	}
+	if _, err := h.Hash(""); err == nil {
+		t.Error("Hash(\"\") succeeded")
+	}
+}
+
+func TestArgon2VerifiesBcrypt(t *testing.T) {
+	legacy := NewBcrypt(WithCost(4))
+	h := NewArgon2id(cheap...)
+	long := strings.Repeat("a", 72)
+	tests := []struct {
+		name, pw, wrong string
+	}{
+		{"short", "correct horse", "correct horsE"},
+		// Beyond 72 bytes bcrypt alone ignores the rest; the prehash must not.
+		{"longer than 72 bytes", long + "tail", long + "tale"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := legacy.Hash(tt.pw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !h.Verify(hash, tt.pw) {
+				t.Error("Verify() of a bcrypt hash failed")
+			}
+			if h.Verify(hash, tt.wrong) {
+				t.Error("Verify() of a wrong password succeeded")
+			}
+			if !h.NeedsRehash(hash) {
+				t.Error("NeedsRehash() of a bcrypt hash = false")
+			}
+		})
+	}
+}
+
+func TestArgon2NeedsRehash(t *testing.T) {
+	h := NewArgon2id(WithArgon2Memory(128), WithArgon2Time(2), WithArgon2Parallelism(2))
+	tests := []struct {
+		name string
+		from Hasher
+		want bool
+	}{
+		{"same parameters", NewArgon2id(WithArgon2Memory(128), WithArgon2Time(2), WithArgon2Parallelism(2)), false},
+		{"higher cost", NewArgon2id(WithArgon2Memory(256), WithArgon2Time(3), WithArgon2Parallelism(2)), false},
+		{"less memory", NewArgon2id(WithArgon2Memory(64), WithArgon2Time(2), WithArgon2Parallelism(2)), true},
+		{"fewer passes", NewArgon2id(WithArgon2Memory(128), WithArgon2Time(1), WithArgon2Parallelism(2)), true},
+		{"other parallelism", NewArgon2id(WithArgon2Memory(128), WithArgon2Time(2), WithArgon2Parallelism(1)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := tt.from.Hash("correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := h.NeedsRehash(hash); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+			// Hashes with other parameters still verify.
+			if !h.Verify(hash, "correct horse") {
+				t.Error("Verify() failed")
+			}
+		})
+	}
+}
+
+func TestArgon2RejectsMalformedHashes(t *testing.T) {
+	h := NewArgon2id(cheap...)
+	good, err := h.Hash("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(good, "$")
+	for _, hash := range []string{
+		"$argon2id$",
+		"$argon2id$v=18$" + strings.Join(parts[3:], "$"),
+		"$argon2id$v=19$m=64,t=0,p=1$" + strings.Join(parts[4:], "$"),
+		"$argon2id$v=19$m=64,t=1,p=1$!!!$" + parts[5],
+		strings.Join(parts[:5], "$") + "$",
+	} {
+		if h.Verify(hash, "pw") {
+			t.Errorf("Verify(%q) succeeded", hash)
+		}
+		if !h.NeedsRehash(hash) {
+			t.Errorf("NeedsRehash(%q) = false", hash)
+		}
+	}
+}
+
+func TestBcryptVerifiesArgon2(t *testing.T) {
+	hash, err := NewArgon2id(cheap...).Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewBcrypt(WithCost(4))
+	if !b.Verify(hash, "correct horse") {
+		t.Error("Verify() of an argon2id hash failed")
+	}
+	if b.Verify(hash, "wrong") {
+		t.Error("Verify() of a wrong password succeeded")
+	}
+	if !b.NeedsRehash(hash) {
+		t.Error("NeedsRehash() of an argon2id hash = false")
+	}
+}
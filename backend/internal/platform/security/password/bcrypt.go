@@ -8,6 +8,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Hasher hashes and verifies passwords. NeedsRehash reports whether a hash
+// that verified should be replaced by a fresh Hash, for instance after the
+// cost was raised or the algorithm changed.
 type Hasher interface {
 	Hash(pw string) (string, error)
 	Verify(hash, pw string) bool
@@ -65,7 +68,12 @@ func (b *bcryptHasher) Hash(password string) (string, error) {
 	return string(hash), nil
 }
 
+// Verify also accepts argon2id hashes, so that going back from argon2id to
+// bcrypt does not lock out users whose hashes migrated.
 func (b *bcryptHasher) Verify(hash, password string) bool {
+	if isArgon2(hash) {
+		return verifyArgon2(hash, password)
+	}
 	pw := []byte(password)
 	if b.prehashLong && len(pw) > 72 {
 		pw = prehash(pw)
@@ -74,6 +82,9 @@ func (b *bcryptHasher) Verify(hash, password string) bool {
 }
 
 func (b *bcryptHasher) NeedsRehash(hash string) bool {
+	if isArgon2(hash) {
+		return true
+	}
 	c, err := bcrypt.Cost([]byte(hash))
 	return err == nil && c < b.cost
 }
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{"users/1/abc", false},
+		{"users/1/a b+c(1)~.pdf", false},
+		{"", true},
+		{"/etc/passwd", true},
+		{"../secret", true},
+		{"users/../../secret", true},
+		{"users/./1", true},
+		{"users//1", true},
+		{"users/1/", true},
+		{`users\..\secret`, true},
+	}
+	for _, tt := range tests {
+		err := checkKey(tt.key)
+		if tt.wantErr && !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("checkKey(%q) = %v, want ErrInvalidKey", tt.key, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("checkKey(%q) = %v, want nil", tt.key, err)
+		}
+	}
+}
+
+func TestLocalStoreRejectsTraversal(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	s, err := NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "../outside", strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put() error = %v, want ErrInvalidKey", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outside")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Put() wrote outside the root: %v", err)
+	}
+	if _, err := s.Open(ctx, "../root/x"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Open() error = %v, want ErrInvalidKey", err)
+	}
+	if err := s.Delete(ctx, "/tmp/x"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Delete() error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestLocalStorePut(t *testing.T) {
+	s, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	key := "users/1/abc"
+
+	if err := s.Put(ctx, key, strings.NewReader("first"), 5, ""); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// A short read must neither replace the object nor leave a temporary
+	// file behind.
+	if err := s.Put(ctx, key, strings.NewReader("sec"), 6, ""); err == nil {
+		t.Fatal("Put() with a short body succeeded")
+	}
+	assertObject(t, s, key, "first")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := s.Put(cancelled, key, strings.NewReader("third"), 5, ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Put() with a cancelled context error = %v", err)
+	}
+	assertObject(t, s, key, "first")
+
+	if err := s.Put(ctx, key, strings.NewReader("second"), 6, ""); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	assertObject(t, s, key, "second")
+
+	entries, err := os.ReadDir(filepath.Join(s.root, "users", "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want only the object", len(entries))
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() after Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func assertObject(t *testing.T, s Store, key, want string) {
+	t.Helper()
+	r, err := s.Open(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer r.Close()
+	got, _ := io.ReadAll(r)
+	if string(got) != want {
+		t.Errorf("object = %q, want %q", got, want)
+	}
+}
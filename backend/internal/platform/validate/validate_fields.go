@@ -0,0 +1,67 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldError describes why a single input field is invalid. Field is the
+// JSON path of the field, e.g. "employment.salaryRange.currency".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors collects the field errors of one input so that all of them can be
+// reported at once. Its zero value is ready to use.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, f := range e {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Err returns e as an error, or nil if nothing was collected.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *Errors) Add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// Required records an error for every blank field: a nil value, or a string
+// or *string that is nil or only whitespace. Other values count as present.
+func (e *Errors) Required(fields ...Field) {
+	for _, f := range fields {
+		if isBlank(f.Value) {
+			e.Add(f.Name, "is required")
+		}
+	}
+}
+
+// Currency records an error unless code is an active ISO 4217 currency code.
+func (e *Errors) Currency(field, code string) {
+	if !IsCurrency(code) {
+		e.Add(field, "must be an ISO 4217 currency code")
+	}
+}
+
+// OneOf records an error unless v is one of allowed.
+func OneOf[T comparable](e *Errors, field string, v T, allowed ...T) {
+	if slices.Contains(allowed, v) {
+		return
+	}
+	names := make([]string, 0, len(allowed))
+	for _, a := range allowed {
+		names = append(names, fmt.Sprint(a))
+	}
+	e.Add(field, "must be one of "+strings.Join(names, ", "))
+}
@@ -1,7 +1,6 @@
 package validate
 
 import (
-	"errors"
 	"strings"
 )
 
@@ -10,13 +9,11 @@ type Field struct {
 	Value any
 }
 
+// Required reports every blank field as validate.Errors.
 func Required(fields ...Field) error {
-	for _, f := range fields {
-		if isBlank(f.Value) {
-			return errors.New("a " + f.Name + " is required")
-		}
-	}
-	return nil
+	var errs Errors
+	errs.Required(fields...)
+	return errs.Err()
 }
 
 func isBlank(v any) bool {
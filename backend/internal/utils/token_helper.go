@@ -1,35 +0,0 @@
-package utils
-
-import (
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/spf13/viper"
-)
-
-type Claims struct {
-    UserID int `json:"user_id"`
-    jwt.RegisteredClaims
-}
-
-var JwtSecret = viper.GetString("JWT_SECRET")
-
-func GenerateJWT(userID int) (string, error) {
-    secretKey := []byte(JwtSecret)
-
-    claims := Claims{
-        UserID: userID,
-        RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-        },
-    }
-
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    signedToken, err := token.SignedString(secretKey)
-    if err != nil {
-        return "", err
-    }
-
-    return signedToken, nil
-}
-